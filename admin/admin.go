@@ -0,0 +1,90 @@
+// Package admin exposes a round-robin over HTTP for operational visibility. It is kept separate from the core
+// package so that importing hq-go-roundrobin does not pull in net/http.
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+
+	hqgoroundrobin "github.com/hueristiq/hq-go-roundrobin"
+)
+
+// Stats is the JSON document served on GET, describing the items of the round-robin and how often each of
+// them has been served.
+type Stats struct {
+	// Items lists the items in rotation order.
+	Items []ItemStats `json:"items"`
+	// TotalServes is the sum of the serve counts of all items.
+	TotalServes int64 `json:"total_serves"`
+}
+
+// ItemStats describes a single item of the round-robin.
+type ItemStats struct {
+	// Value is the value of the item.
+	Value string `json:"value"`
+	// Serves is the number of times the item has been served.
+	Serves int32 `json:"serves"`
+}
+
+// AddRequest is the JSON document accepted on POST, listing the values to add to the round-robin.
+type AddRequest struct {
+	// Values lists the values to add.
+	Values []string `json:"values"`
+}
+
+// Handler returns an http.Handler for the given round-robin. A GET request responds with the current Stats as
+// JSON, and a POST request with an AddRequest body adds its values to the round-robin. All access goes through
+// the concurrency-safe methods of the round-robin.
+func Handler(rr *hqgoroundrobin.RoundRobin) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.Method {
+		case http.MethodGet:
+			serveStats(w, rr)
+		case http.MethodPost:
+			serveAdd(w, req, rr)
+		default:
+			w.Header().Set("Allow", "GET, POST")
+
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+// serveStats writes the current statistics of the round-robin as JSON.
+func serveStats(w http.ResponseWriter, rr *hqgoroundrobin.RoundRobin) {
+	stats := Stats{
+		Items: []ItemStats{},
+	}
+
+	for value, serves := range rr.Stats() {
+		stats.Items = append(stats.Items, ItemStats{
+			Value:  value,
+			Serves: serves,
+		})
+
+		stats.TotalServes += int64(serves)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	_ = json.NewEncoder(w).Encode(stats)
+}
+
+// serveAdd decodes an AddRequest from the request body and adds its values to the round-robin.
+func serveAdd(w http.ResponseWriter, req *http.Request, rr *hqgoroundrobin.RoundRobin) {
+	var body AddRequest
+
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	if err := rr.AddErr(body.Values...); err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+
+		return
+	}
+
+	serveStats(w, rr)
+}
@@ -0,0 +1,70 @@
+package admin_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	hqgoroundrobin "github.com/hueristiq/hq-go-roundrobin"
+	"github.com/hueristiq/hq-go-roundrobin/admin"
+)
+
+func TestHandlerGet(t *testing.T) {
+	t.Parallel()
+
+	rr, _ := hqgoroundrobin.New("item1", "item2")
+
+	rr.Next()
+
+	recorder := httptest.NewRecorder()
+
+	admin.Handler(rr).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Unexpected status code: got %d, want %d", recorder.Code, http.StatusOK)
+	}
+
+	var stats admin.Stats
+
+	if err := json.NewDecoder(recorder.Body).Decode(&stats); err != nil {
+		t.Fatalf("Failed to decode the response body: %s", err)
+	}
+
+	if len(stats.Items) != 2 || stats.Items[0].Value != "item1" || stats.Items[0].Serves != 1 || stats.TotalServes != 1 {
+		t.Errorf("Response body was incorrect: got %+v", stats)
+	}
+}
+
+func TestHandlerPost(t *testing.T) {
+	t.Parallel()
+
+	rr, _ := hqgoroundrobin.NewWithOptions(hqgoroundrobin.Options{RotateAmount: 1, ErrorOnDuplicate: true}, "item1")
+
+	handler := admin.Handler(rr)
+
+	recorder := httptest.NewRecorder()
+
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"values":["item2"]}`)))
+
+	if recorder.Code != http.StatusOK || len(rr.Values()) != 2 {
+		t.Errorf("Values were not added: got status %d and %d items", recorder.Code, len(rr.Values()))
+	}
+
+	recorder = httptest.NewRecorder()
+
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"values":["item1"]}`)))
+
+	if recorder.Code != http.StatusConflict {
+		t.Errorf("Unexpected status code for a duplicate: got %d, want %d", recorder.Code, http.StatusConflict)
+	}
+
+	recorder = httptest.NewRecorder()
+
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodDelete, "/", http.NoBody))
+
+	if recorder.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Unexpected status code for an unsupported method: got %d, want %d", recorder.Code, http.StatusMethodNotAllowed)
+	}
+}
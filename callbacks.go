@@ -0,0 +1,60 @@
+package roundrobin
+
+// serveEvent is a serve or a rotation waiting to be reported to the callbacks of Options once the mutex is
+// released. The callbacks are captured when the event happens, so later option changes do not affect it.
+type serveEvent struct {
+	// onServe is the callback reporting a serve, or nil for a rotation.
+	onServe func(item Item)
+	// onRotate is the callback reporting a rotation, or nil for a serve.
+	onRotate func(from, to Item)
+	// from is the item the rotation advanced from.
+	from Item
+	// to is the item served, or the item the rotation advanced to.
+	to Item
+}
+
+// served records that the item at the given index was served, in its history and for Options.OnServe. It must be
+// called with the mutex held.
+func (r *RoundRobin) served(index int) {
+	r.recordHistory(r.items[index].value, HistoryServed, "")
+	r.recordQuota()
+
+	if r.Options.OnServe != nil {
+		r.events = append(r.events, serveEvent{
+			onServe: r.Options.OnServe,
+			to:      r.items[index],
+		})
+	}
+}
+
+// rotatedTo records for Options.OnRotate that the rotation advanced from the item at index from to the item at
+// index to. It must be called with the mutex held.
+func (r *RoundRobin) rotatedTo(from, to int) {
+	if r.Options.OnRotate != nil {
+		r.events = append(r.events, serveEvent{
+			onRotate: r.Options.OnRotate,
+			from:     r.items[from],
+			to:       r.items[to],
+		})
+	}
+}
+
+// unlock releases the mutex and then reports the pending serves and rotations to their callbacks, so that the
+// callbacks may call back into the round-robin without deadlocking.
+func (r *RoundRobin) unlock() {
+	events := r.events
+
+	r.events = nil
+
+	r.mutex.Unlock()
+
+	for _, event := range events {
+		if event.onRotate != nil {
+			event.onRotate(event.from, event.to)
+
+			continue
+		}
+
+		event.onServe(event.to)
+	}
+}
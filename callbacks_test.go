@@ -0,0 +1,55 @@
+package roundrobin_test
+
+import (
+	"slices"
+	"testing"
+
+	hqgoroundrobin "github.com/hueristiq/hq-go-roundrobin"
+)
+
+func TestCallbacks(t *testing.T) {
+	t.Parallel()
+
+	var (
+		rr        *hqgoroundrobin.RoundRobin
+		served    []string
+		rotations []string
+	)
+
+	options := hqgoroundrobin.Options{
+		RotateAmount: 2,
+		OnServe: func(item hqgoroundrobin.Item) {
+			// The mutex is released before callbacks run, so they may use the round-robin.
+			if len(rr.Values()) == 3 {
+				served = append(served, item.Value())
+			}
+		},
+		OnRotate: func(from, to hqgoroundrobin.Item) {
+			rotations = append(rotations, from.Value()+">"+to.Value())
+		},
+	}
+
+	rr, _ = hqgoroundrobin.NewWithOptions(options, "item1", "item2", "item3")
+
+	for range 6 {
+		rr.Next()
+	}
+
+	rr.NextN(4)
+
+	if _, err := rr.Serve("item1"); err != nil {
+		t.Fatalf("Failed to serve an item: %s", err)
+	}
+
+	wantServed := []string{"item1", "item1", "item2", "item2", "item3", "item3", "item1", "item1", "item2", "item2", "item1"}
+
+	if !slices.Equal(served, wantServed) {
+		t.Errorf("Unexpected serves: got %v, want %v", served, wantServed)
+	}
+
+	wantRotations := []string{"item1>item2", "item2>item3", "item3>item1", "item1>item2"}
+
+	if !slices.Equal(rotations, wantRotations) {
+		t.Errorf("Unexpected rotations: got %v, want %v", rotations, wantRotations)
+	}
+}
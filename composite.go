@@ -0,0 +1,150 @@
+package roundrobin
+
+import (
+	"sync"
+)
+
+// WeightedPool is a round-robin that takes part in a Composite with the given weight.
+type WeightedPool struct {
+	// Pool is the sub-pool serving the items.
+	Pool *RoundRobin
+	// Weight is the relative share of serves routed to the sub-pool. Weights below 1 are treated as 1.
+	Weight int
+}
+
+// Composite is a two-level balancer: it routes each serve to one of its sub-pools by smooth weighted round-robin
+// selection, and the selected sub-pool serves its next item in its own rotation. For example, two regional pools
+// weighted 7 and 3 receive 70% and 30% of the serves, each rotating evenly over its own items.
+type Composite struct {
+	// pools holds the sub-pools in the order they were given.
+	pools []compositePool
+	// mutex guards the running weights of the sub-pools.
+	mutex sync.Mutex
+}
+
+// compositePool is a sub-pool of a Composite together with its smooth weighted round-robin state.
+type compositePool struct {
+	// rr is the sub-pool.
+	rr *RoundRobin
+	// weight is the relative share of serves routed to the sub-pool.
+	weight int
+	// currentWeight is the running weight used by smooth weighted round-robin selection.
+	currentWeight int
+}
+
+// Next serves the next item of a sub-pool picked by weight. It returns a zero Item if no sub-pool has an eligible
+// item; use NextE to distinguish that case.
+func (c *Composite) Next() (item Item) {
+	item, _ = c.NextE()
+
+	return
+}
+
+// NextE serves the next item of a sub-pool picked by weight. Sub-pools without eligible items are skipped, and
+// ErrNoItems is returned if none of them has one.
+func (c *Composite) NextE() (item Item, err error) {
+	c.mutex.Lock()
+
+	defer c.mutex.Unlock()
+
+	exhausted := make([]bool, len(c.pools))
+
+	for {
+		index := -1
+
+		for i := range c.pools {
+			if exhausted[i] {
+				continue
+			}
+
+			if index < 0 || c.pools[i].currentWeight+c.pools[i].weight > c.pools[index].currentWeight+c.pools[index].weight {
+				index = i
+			}
+		}
+
+		if index < 0 {
+			err = ErrNoItems
+
+			return
+		}
+
+		if item, err = c.pools[index].rr.NextE(); err != nil {
+			exhausted[index] = true
+
+			continue
+		}
+
+		total := 0
+
+		for i := range c.pools {
+			if exhausted[i] {
+				continue
+			}
+
+			c.pools[i].currentWeight += c.pools[i].weight
+
+			total += c.pools[i].weight
+		}
+
+		c.pools[index].currentWeight -= total
+
+		return
+	}
+}
+
+// StatsMap returns the serve counts of the items of all sub-pools, summing the counts of values that appear in
+// several of them.
+func (c *Composite) StatsMap() (stats map[string]int32) {
+	stats = make(map[string]int32)
+
+	for i := range c.pools {
+		for value, count := range c.pools[i].rr.StatsMap() {
+			stats[value] += count
+		}
+	}
+
+	return
+}
+
+// TotalServes returns the sum of the serve counts of all sub-pools.
+func (c *Composite) TotalServes() (total int64) {
+	for i := range c.pools {
+		total += c.pools[i].rr.TotalServes()
+	}
+
+	return
+}
+
+// PoolServes returns the total serve count of each sub-pool, in the order the sub-pools were given.
+func (c *Composite) PoolServes() (totals []int64) {
+	totals = make([]int64, len(c.pools))
+
+	for i := range c.pools {
+		totals[i] = c.pools[i].rr.TotalServes()
+	}
+
+	return
+}
+
+// NewComposite creates a Composite routing serves to the given sub-pools by weight. It returns ErrNoItems if no
+// sub-pools are provided.
+func NewComposite(pools ...WeightedPool) (composite *Composite, err error) {
+	if len(pools) == 0 {
+		err = ErrNoItems
+
+		return
+	}
+
+	composite = &Composite{
+		pools: make([]compositePool, 0, len(pools)),
+	}
+
+	for _, pool := range pools {
+		composite.pools = append(composite.pools, compositePool{
+			rr:     pool.Pool,
+			weight: max(pool.Weight, 1),
+		})
+	}
+
+	return
+}
@@ -0,0 +1,64 @@
+package roundrobin_test
+
+import (
+	"errors"
+	"testing"
+
+	hqgoroundrobin "github.com/hueristiq/hq-go-roundrobin"
+)
+
+func TestComposite(t *testing.T) {
+	t.Parallel()
+
+	regionA, _ := hqgoroundrobin.New("a1", "a2")
+	regionB, _ := hqgoroundrobin.New("b1", "b2", "b3")
+
+	composite, err := hqgoroundrobin.NewComposite(
+		hqgoroundrobin.WeightedPool{Pool: regionA, Weight: 7},
+		hqgoroundrobin.WeightedPool{Pool: regionB, Weight: 3},
+	)
+	if err != nil {
+		t.Fatalf("Failed to create a composite: %s", err)
+	}
+
+	for range 600 {
+		composite.Next()
+	}
+
+	if got := composite.PoolServes(); got[0] != 420 || got[1] != 180 {
+		t.Errorf("Unexpected split between sub-pools: got %v, want [420 180]", got)
+	}
+
+	stats := composite.StatsMap()
+
+	for value, want := range map[string]int32{"a1": 210, "a2": 210, "b1": 60, "b2": 60, "b3": 60} {
+		if got := stats[value]; got != want {
+			t.Errorf("Unexpected serves for %s: got %d, want %d", value, got, want)
+		}
+	}
+
+	if got := composite.TotalServes(); got != 600 {
+		t.Errorf("Unexpected total serves: got %d, want %d", got, 600)
+	}
+
+	_ = regionA.Disable("a1")
+	_ = regionA.Disable("a2")
+
+	for range 3 {
+		if item := composite.Next(); item.Value()[0] != 'b' {
+			t.Errorf("Unexpected item from an exhausted sub-pool: %s", item.Value())
+		}
+	}
+
+	_ = regionB.Disable("b1")
+	_ = regionB.Disable("b2")
+	_ = regionB.Disable("b3")
+
+	if _, err := composite.NextE(); !errors.Is(err, hqgoroundrobin.ErrNoItems) {
+		t.Errorf("Expected ErrNoItems error, got %v", err)
+	}
+
+	if _, err := hqgoroundrobin.NewComposite(); !errors.Is(err, hqgoroundrobin.ErrNoItems) {
+		t.Errorf("Expected ErrNoItems error, got %v", err)
+	}
+}
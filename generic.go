@@ -0,0 +1,152 @@
+package roundrobin
+
+import (
+	"errors"
+	"sync"
+)
+
+// GenericItem represents a single unit within a Generic round-robin collection. It holds a value of any type and
+// the statistics of how many times it has been served.
+type GenericItem[T any] struct {
+	// value is the content of the item.
+	value T
+	// Statistics holds metrics related to the item, such as its serve count.
+	Statistics Statistics
+}
+
+// Value returns the underlying value of the item.
+func (i GenericItem[T]) Value() (value T) {
+	return i.value
+}
+
+// Generic manages a collection of values of any type in a round-robin fashion. Values are identified by the key
+// returned by the configured key function, since T may not be comparable, and the rotation itself is delegated
+// to a string-keyed RoundRobin, so a Generic behaves exactly like a RoundRobin over those keys.
+type Generic[T any] struct {
+	// rr rotates through the keys of the values.
+	rr *RoundRobin
+	// keyFunc returns the key identifying a value.
+	keyFunc func(value T) string
+	// values maps each key to the value it was first added with.
+	values sync.Map
+}
+
+// Add inserts one or more new values into the round-robin collection, ignoring values whose key is already
+// present.
+func (g *Generic[T]) Add(values ...T) {
+	keys := make([]string, 0, len(values))
+
+	for _, value := range values {
+		key := g.keyFunc(value)
+
+		// Store the value before its key joins the rotation, so a served key always resolves to its value.
+		g.values.LoadOrStore(key, value)
+
+		keys = append(keys, key)
+	}
+
+	g.rr.Add(keys...)
+}
+
+// Next retrieves the next item in the round-robin order, or a zero GenericItem if there are no items.
+func (g *Generic[T]) Next() (item GenericItem[T]) {
+	item, _ = g.NextE()
+
+	return
+}
+
+// NextE retrieves the next item in the round-robin order like Next, but returns ErrNoItems if there are no
+// items.
+func (g *Generic[T]) NextE() (item GenericItem[T], err error) {
+	served, err := g.rr.NextE()
+	if err != nil {
+		return
+	}
+
+	item = g.item(served)
+
+	return
+}
+
+// Items returns a copy of the items in rotation order.
+func (g *Generic[T]) Items() (items []GenericItem[T]) {
+	g.rr.mutex.Lock()
+
+	defer g.rr.mutex.Unlock()
+
+	items = make([]GenericItem[T], 0, len(g.rr.items))
+
+	for _, item := range g.rr.items {
+		items = append(items, g.item(item))
+	}
+
+	return
+}
+
+// Len returns the number of items in the round-robin.
+func (g *Generic[T]) Len() (length int) {
+	g.rr.mutex.Lock()
+
+	defer g.rr.mutex.Unlock()
+
+	return len(g.rr.items)
+}
+
+// item converts an item of the underlying key rotation into the item holding the corresponding value.
+func (g *Generic[T]) item(keyed Item) (item GenericItem[T]) {
+	item.Statistics = keyed.Statistics
+
+	if value, ok := g.values.Load(keyed.value); ok {
+		item.value, _ = value.(T)
+	}
+
+	return
+}
+
+// GenericOptions holds configuration settings for a Generic round-robin. It extends Options with the function
+// identifying values.
+type GenericOptions[T any] struct {
+	Options
+
+	// KeyFunc returns the key identifying a value. Values with the same key are considered duplicates.
+	KeyFunc func(value T) string
+}
+
+// ErrNilKeyFunc indicates that a Generic round-robin was configured without a key function.
+var ErrNilKeyFunc = errors.New("nil key function")
+
+// NewGeneric creates a new Generic round-robin with the given options and initial items. It returns ErrNilKeyFunc
+// if no key function is configured and ErrNoItems if no items are provided.
+func NewGeneric[T any](options GenericOptions[T], items ...T) (g *Generic[T], err error) {
+	if options.KeyFunc == nil {
+		err = ErrNilKeyFunc
+
+		return
+	}
+
+	if len(items) == 0 {
+		err = ErrNoItems
+
+		return
+	}
+
+	g = &Generic[T]{
+		keyFunc: options.KeyFunc,
+	}
+
+	keys := make([]string, 0, len(items))
+
+	for _, item := range items {
+		key := g.keyFunc(item)
+
+		g.values.LoadOrStore(key, item)
+
+		keys = append(keys, key)
+	}
+
+	if g.rr, err = NewWithOptions(options.Options, keys...); err != nil {
+		g = nil
+	}
+
+	return
+}
@@ -0,0 +1,67 @@
+package roundrobin_test
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	hqgoroundrobin "github.com/hueristiq/hq-go-roundrobin"
+)
+
+func TestGenericInt(t *testing.T) {
+	t.Parallel()
+
+	options := hqgoroundrobin.GenericOptions[int]{
+		Options: hqgoroundrobin.DefaultOptions,
+		KeyFunc: strconv.Itoa,
+	}
+
+	rr, err := hqgoroundrobin.NewGeneric(options, 1, 2, 2)
+	if err != nil {
+		t.Fatalf("Failed to create a new Generic instance: %s", err)
+	}
+
+	rr.Add(3, 1)
+
+	if length := rr.Len(); length != 3 {
+		t.Errorf("Duplicates were not ignored: got %d items, want %d", length, 3)
+	}
+
+	for _, want := range []int{1, 2, 3, 1} {
+		if item := rr.Next(); item.Value() != want {
+			t.Errorf("Serving order was incorrect: got %d, want %d", item.Value(), want)
+		}
+	}
+}
+
+func TestGenericStruct(t *testing.T) {
+	t.Parallel()
+
+	type upstream struct {
+		host string
+		port int
+	}
+
+	options := hqgoroundrobin.GenericOptions[upstream]{
+		Options: hqgoroundrobin.DefaultOptions,
+		KeyFunc: func(u upstream) string {
+			return u.host + ":" + strconv.Itoa(u.port)
+		},
+	}
+
+	rr, _ := hqgoroundrobin.NewGeneric(options, upstream{"a", 80}, upstream{"b", 80})
+
+	for range 4 {
+		rr.Next()
+	}
+
+	for _, item := range rr.Items() {
+		if item.Statistics.ServesCount != 2 {
+			t.Errorf("Item %s was not served evenly: got %d, want %d", item.Value().host, item.Statistics.ServesCount, 2)
+		}
+	}
+
+	if _, err := hqgoroundrobin.NewGeneric(hqgoroundrobin.GenericOptions[upstream]{Options: hqgoroundrobin.DefaultOptions}, upstream{"a", 80}); !errors.Is(err, hqgoroundrobin.ErrNilKeyFunc) {
+		t.Errorf("Expected ErrNilKeyFunc error, got %v", err)
+	}
+}
@@ -0,0 +1,123 @@
+package roundrobin
+
+import (
+	"time"
+)
+
+// HistoryEvent identifies the kind of an entry in an item's history.
+type HistoryEvent string
+
+const (
+	// HistoryAdded records that the item was added to the round-robin, with its weight as detail.
+	HistoryAdded HistoryEvent = "added"
+	// HistoryServed records that the item was served.
+	HistoryServed HistoryEvent = "served"
+	// HistoryDisabled records that the item was disabled.
+	HistoryDisabled HistoryEvent = "disabled"
+	// HistoryEnabled records that the item was enabled.
+	HistoryEnabled HistoryEvent = "enabled"
+	// HistoryCooldown records that the item was benched, with the cooldown duration as detail.
+	HistoryCooldown HistoryEvent = "cooldown"
+)
+
+// HistoryEntry is a single timestamped event in the history of an item.
+type HistoryEntry struct {
+	// Event is the kind of the event.
+	Event HistoryEvent
+	// At is the time the event happened, as reported by Options.Now.
+	At time.Time
+	// Detail holds event-specific information, such as the duration of a cooldown.
+	Detail string
+}
+
+// historyRing is a fixed-size ring buffer of history entries, overwriting the oldest entry once full.
+type historyRing struct {
+	// entries holds the recorded entries.
+	entries []HistoryEntry
+	// next is the position the next entry is written to.
+	next int
+	// full reports whether the ring has wrapped around.
+	full bool
+}
+
+// push records an entry, overwriting the oldest one if the ring is full.
+func (h *historyRing) push(entry HistoryEntry) {
+	h.entries[h.next] = entry
+
+	h.next = (h.next + 1) % len(h.entries)
+
+	h.full = h.full || h.next == 0
+}
+
+// ordered returns a copy of the recorded entries, oldest first.
+func (h *historyRing) ordered() (entries []HistoryEntry) {
+	if !h.full {
+		return append([]HistoryEntry{}, h.entries[:h.next]...)
+	}
+
+	entries = make([]HistoryEntry, 0, len(h.entries))
+	entries = append(entries, h.entries[h.next:]...)
+	entries = append(entries, h.entries[:h.next]...)
+
+	return
+}
+
+// ItemHistory returns the recent events of the item with the given value, oldest first. Up to
+// Options.TrackHistory events are kept per item; the history is empty if tracking is disabled. It returns
+// ErrItemNotFound if no such item exists.
+func (r *RoundRobin) ItemHistory(value string) (entries []HistoryEntry, err error) {
+	r.mutex.Lock()
+
+	defer r.mutex.Unlock()
+
+	if _, ok := r.itemsMap.Load(value); !ok {
+		err = ErrItemNotFound
+
+		return
+	}
+
+	entries = []HistoryEntry{}
+
+	if ring, ok := r.histories[value]; ok {
+		entries = ring.ordered()
+	}
+
+	return
+}
+
+// recordHistory appends an event to the history of the item with the given value, if history tracking is
+// enabled. It must be called with the mutex held.
+func (r *RoundRobin) recordHistory(value string, event HistoryEvent, detail string) {
+	if r.Options.TrackHistory <= 0 {
+		return
+	}
+
+	if r.histories == nil {
+		r.histories = make(map[string]*historyRing)
+	}
+
+	ring, ok := r.histories[value]
+	if !ok || len(ring.entries) != r.Options.TrackHistory {
+		ring = &historyRing{
+			entries: make([]HistoryEntry, r.Options.TrackHistory),
+		}
+
+		r.histories[value] = ring
+	}
+
+	ring.push(HistoryEntry{
+		Event:  event,
+		At:     r.now(),
+		Detail: detail,
+	})
+}
+
+// pruneHistories drops the histories of items that are no longer part of the round-robin. It must be called with
+// the mutex held.
+func (r *RoundRobin) pruneHistories() {
+	for value := range r.histories {
+		if _, ok := r.itemsMap.Load(value); !ok {
+			delete(r.histories, value)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package roundrobin
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// roundRobinJSON is the JSON representation of a RoundRobin, capturing everything needed to continue the
+// rotation where it left off.
+type roundRobinJSON struct {
+	Items                  []itemJSON  `json:"items"`
+	NextItemIndex          uint32      `json:"next_item_index"`
+	CurrentItemServesCount uint32      `json:"current_item_serves_count"`
+	Options                optionsJSON `json:"options"`
+}
+
+// itemJSON is the JSON representation of an Item.
+type itemJSON struct {
+	Value         string    `json:"value"`
+	ServesCount   int32     `json:"serves_count"`
+	Weight        int       `json:"weight"`
+	CurrentWeight int       `json:"current_weight,omitempty"`
+	RotateAmount  int32     `json:"rotate_amount,omitempty"`
+	MaxServes     int32     `json:"max_serves,omitempty"`
+	Disabled      bool      `json:"disabled,omitempty"`
+	CooldownUntil time.Time `json:"cooldown_until,omitzero"`
+}
+
+// optionsJSON is the JSON representation of Options. Function fields cannot be serialized and are left out.
+type optionsJSON struct {
+	RotateAmount        int32 `json:"rotate_amount"`
+	StatsPaused         bool  `json:"stats_paused,omitempty"`
+	ErrorOnDuplicate    bool  `json:"error_on_duplicate,omitempty"`
+	TrackContention     bool  `json:"track_contention,omitempty"`
+	InitialServes       int32 `json:"initial_serves,omitempty"`
+	CountOnComplete     bool  `json:"count_on_complete,omitempty"`
+	SerializedMode      bool  `json:"serialized_mode,omitempty"`
+	ErrorOnUnknownStats bool  `json:"error_on_unknown_stats,omitempty"`
+	TrackHistory        int   `json:"track_history,omitempty"`
+	QuotaPerWindow      int   `json:"quota_per_window,omitempty"`
+	QuotaWindow         int64 `json:"quota_window,omitempty"`
+}
+
+// encodeOptions returns the JSON representation of the given options.
+func encodeOptions(options Options) (encoded optionsJSON) {
+	return optionsJSON{
+		RotateAmount:        options.RotateAmount,
+		StatsPaused:         options.StatsPaused,
+		ErrorOnDuplicate:    options.ErrorOnDuplicate,
+		TrackContention:     options.TrackContention,
+		InitialServes:       options.InitialServes,
+		CountOnComplete:     options.CountOnComplete,
+		SerializedMode:      options.SerializedMode,
+		ErrorOnUnknownStats: options.ErrorOnUnknownStats,
+		TrackHistory:        options.TrackHistory,
+		QuotaPerWindow:      options.QuotaPerWindow,
+		QuotaWindow:         int64(options.QuotaWindow),
+	}
+}
+
+// MarshalJSON encodes the state of the round-robin as JSON: the items in rotation order with their statistics,
+// the rotation position and the options. Function options, such as Now, are not encoded.
+func (r *RoundRobin) MarshalJSON() (data []byte, err error) {
+	r.mutex.Lock()
+
+	state := roundRobinJSON{
+		Items:                  make([]itemJSON, len(r.items)),
+		NextItemIndex:          r.nextItemIndex,
+		CurrentItemServesCount: r.currentItemServesCount,
+		Options:                encodeOptions(r.Options),
+	}
+
+	for i, item := range r.items {
+		state.Items[i] = itemJSON{
+			Value:         item.value,
+			ServesCount:   atomic.LoadInt32(&r.items[i].Statistics.ServesCount),
+			Weight:        item.weight,
+			CurrentWeight: item.currentWeight,
+			RotateAmount:  item.rotateAmount,
+			MaxServes:     item.maxServes,
+			Disabled:      !item.enabled,
+			CooldownUntil: item.cooldownUntil,
+		}
+	}
+
+	r.mutex.Unlock()
+
+	return json.Marshal(state) //nolint:wrapcheck // MarshalJSON errors are wrapped by encoding/json itself.
+}
+
+// UnmarshalJSON restores the state of the round-robin from JSON produced by MarshalJSON, replacing its items,
+// rotation position and options, so that the next call to Next continues where the encoded round-robin left
+// off. Function options, such as Now, are kept as they are, while the histories of removed items and the serves
+// counted against the quota are dropped. It returns ErrInvalidRotateAmount, leaving the round-robin unchanged, if
+// the encoded rotate amount is below 1.
+func (r *RoundRobin) UnmarshalJSON(data []byte) (err error) {
+	var state roundRobinJSON
+
+	if err = json.Unmarshal(data, &state); err != nil {
+		return //nolint:wrapcheck // UnmarshalJSON errors are wrapped by encoding/json itself.
+	}
+
+	if state.Options.RotateAmount < 1 {
+		err = fmt.Errorf("%w: %d", ErrInvalidRotateAmount, state.Options.RotateAmount)
+
+		return
+	}
+
+	r.mutex.Lock()
+
+	defer r.mutex.Unlock()
+
+	r.items = make([]Item, 0, len(state.Items))
+
+	seen := make(map[string]struct{}, len(state.Items))
+
+	for _, encoded := range state.Items {
+		if _, ok := seen[encoded.Value]; ok {
+			continue
+		}
+
+		seen[encoded.Value] = struct{}{}
+
+		item := r.newItem(encoded.Value, encoded.Weight)
+
+		item.currentWeight = encoded.CurrentWeight
+		item.rotateAmount = max(encoded.RotateAmount, 0)
+		item.maxServes = max(encoded.MaxServes, 0)
+		item.enabled = !encoded.Disabled
+		item.cooldownUntil = encoded.CooldownUntil
+		item.Statistics.ServesCount = encoded.ServesCount
+
+		r.items = append(r.items, item)
+	}
+
+	r.syncItemsMap()
+	r.refreshWeighted()
+	r.pruneHistories()
+
+	r.quotaServes = nil
+
+	r.nextItemIndex = state.NextItemIndex
+	r.currentItemServesCount = state.CurrentItemServesCount
+
+	r.Options.RotateAmount = state.Options.RotateAmount
+	r.Options.StatsPaused = state.Options.StatsPaused
+	r.Options.ErrorOnDuplicate = state.Options.ErrorOnDuplicate
+	r.Options.TrackContention = state.Options.TrackContention
+	r.Options.InitialServes = state.Options.InitialServes
+	r.Options.CountOnComplete = state.Options.CountOnComplete
+	r.Options.SerializedMode = state.Options.SerializedMode
+	r.Options.ErrorOnUnknownStats = state.Options.ErrorOnUnknownStats
+	r.Options.TrackHistory = state.Options.TrackHistory
+	r.Options.QuotaPerWindow = state.Options.QuotaPerWindow
+	r.Options.QuotaWindow = time.Duration(state.Options.QuotaWindow)
+
+	r.Options.version++
+
+	r.trackContention.Store(r.Options.TrackContention)
+
+	return
+}
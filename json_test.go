@@ -0,0 +1,94 @@
+package roundrobin_test
+
+import (
+	"encoding/json"
+	"errors"
+	"maps"
+	"slices"
+	"testing"
+	"time"
+
+	hqgoroundrobin "github.com/hueristiq/hq-go-roundrobin"
+)
+
+func TestJSONRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	original, _ := hqgoroundrobin.NewWithOptions(hqgoroundrobin.Options{RotateAmount: 2}, "item1", "item2")
+
+	original.AddWeighted("item3", 3)
+
+	_ = original.Disable("item2")
+
+	for range 5 {
+		original.Next()
+	}
+
+	data, err := json.Marshal(original)
+	if err != nil {
+		t.Fatalf("Failed to marshal the round-robin: %s", err)
+	}
+
+	restored := &hqgoroundrobin.RoundRobin{}
+
+	if err := json.Unmarshal(data, restored); err != nil {
+		t.Fatalf("Failed to unmarshal the round-robin: %s", err)
+	}
+
+	if restored.Options.RotateAmount != 2 {
+		t.Errorf("Options were not restored: got RotateAmount %d, want %d", restored.Options.RotateAmount, 2)
+	}
+
+	if !maps.Equal(restored.StatsMap(), original.StatsMap()) {
+		t.Errorf("Statistics were not restored: got %v, want %v", restored.StatsMap(), original.StatsMap())
+	}
+
+	for i := range 20 {
+		if got, want := restored.Next().Value(), original.Next().Value(); got != want {
+			t.Errorf("Restored round-robin diverged at serve %d: got %s, want %s", i, got, want)
+		}
+	}
+}
+
+func TestUnmarshalJSONReplacesState(t *testing.T) {
+	t.Parallel()
+
+	options := hqgoroundrobin.Options{RotateAmount: 1, TrackHistory: 4, QuotaPerWindow: 2, QuotaWindow: time.Hour}
+
+	source, _ := hqgoroundrobin.NewWithOptions(options, "item2")
+
+	data, err := json.Marshal(source)
+	if err != nil {
+		t.Fatalf("Failed to marshal the round-robin: %s", err)
+	}
+
+	rr, _ := hqgoroundrobin.NewWithOptions(options, "item1", "item2")
+
+	rr.Next()
+	rr.Next()
+
+	if err := json.Unmarshal(data, rr); err != nil {
+		t.Fatalf("Failed to unmarshal the round-robin: %s", err)
+	}
+
+	if _, err := rr.NextE(); err != nil {
+		t.Errorf("Serves of the replaced state counted against the quota: %v", err)
+	}
+
+	// A value re-added after the restore starts a new history instead of inheriting the replaced one.
+	rr.Add("item1")
+
+	if entries, _ := rr.ItemHistory("item1"); len(entries) != 1 || entries[0].Event != hqgoroundrobin.HistoryAdded {
+		t.Errorf("Expected the history of the removed item to be dropped, got %v", entries)
+	}
+
+	invalid := []byte(`{"items":[{"value":"item3","serves_count":0,"weight":1}],"options":{"rotate_amount":0}}`)
+
+	if err := json.Unmarshal(invalid, rr); !errors.Is(err, hqgoroundrobin.ErrInvalidRotateAmount) {
+		t.Errorf("Expected ErrInvalidRotateAmount error, got %v", err)
+	}
+
+	if got, want := rr.Values(), []string{"item2", "item1"}; !slices.Equal(got, want) {
+		t.Errorf("Round-robin was changed by invalid JSON: got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,47 @@
+package roundrobin
+
+import (
+	"time"
+)
+
+// quotaEnabled reports whether Options.QuotaPerWindow and Options.QuotaWindow cap the serves of the pool.
+func (r *RoundRobin) quotaEnabled() (enabled bool) {
+	return r.Options.QuotaPerWindow > 0 && r.Options.QuotaWindow > 0
+}
+
+// quotaExceeded reports whether the pool has used up its quota of serves in the current window, forgetting serves
+// that have left the window. It must be called with the mutex held.
+func (r *RoundRobin) quotaExceeded() (exceeded bool) {
+	if !r.quotaEnabled() {
+		return
+	}
+
+	cutoff := r.now().Add(-r.Options.QuotaWindow)
+
+	expired := 0
+
+	for expired < len(r.quotaServes) && !r.quotaServes[expired].After(cutoff) {
+		expired++
+	}
+
+	r.quotaServes = r.quotaServes[expired:]
+
+	return len(r.quotaServes) >= r.Options.QuotaPerWindow
+}
+
+// quotaRefill returns how long it takes until the oldest serve in the window leaves it, freeing up quota. It must
+// be called with the mutex held, after quotaExceeded.
+func (r *RoundRobin) quotaRefill() (wait time.Duration) {
+	if len(r.quotaServes) == 0 {
+		return
+	}
+
+	return r.quotaServes[0].Add(r.Options.QuotaWindow).Sub(r.now())
+}
+
+// recordQuota counts a serve against the quota of the current window. It must be called with the mutex held.
+func (r *RoundRobin) recordQuota() {
+	if r.quotaEnabled() {
+		r.quotaServes = append(r.quotaServes, r.now())
+	}
+}
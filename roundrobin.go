@@ -1,9 +1,20 @@
 package roundrobin
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"hash/fnv"
+	"iter"
+	"maps"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 )
 
 // Item represents a single unit within the round-robin collection. It holds a value and associated statistics
@@ -11,15 +22,56 @@ import (
 type Item struct {
 	// value is the content or identifier of the item.
 	value string
+	// weight is the relative share of serves the item receives in weighted round-robin selection.
+	weight int
+	// currentWeight is the running weight of the item in smooth weighted round-robin selection.
+	currentWeight int
+	// enabled reports whether the item takes part in the rotation. Disabled items keep their statistics and
+	// position but are skipped.
+	enabled bool
+	// rotateAmount is the number of consecutive serves the item gets before rotating, or zero to use
+	// Options.RotateAmount.
+	rotateAmount int32
+	// maxServes is the number of serves after which the item is exhausted and drops out of the rotation, or zero
+	// for no limit.
+	maxServes int32
+	// cooldownUntil is the time until which the item is benched after a call to Cooldown.
+	cooldownUntil time.Time
+	// generation tells the item apart from earlier items with the same value that were removed, so that handles to
+	// those do not resolve to it.
+	generation uint64
 	// Statistics holds metrics related to the item, such as its serve count.
 	Statistics Statistics
 }
 
+// newItem creates an enabled item with the given value and weight, treating weights below 1 as 1, and assigns it
+// the next generation. It must be called with the mutex held.
+func (r *RoundRobin) newItem(value string, weight int) (item Item) {
+	r.generations++
+
+	return Item{
+		value:      value,
+		weight:     max(weight, 1),
+		enabled:    true,
+		generation: r.generations,
+	}
+}
+
 // Value returns the underlying value of the item. This method allows accessing the item's content.
 func (i Item) Value() (value string) {
 	return i.value
 }
 
+// Weight returns the weight of the item, its relative share of serves in weighted round-robin selection.
+func (i Item) Weight() (weight int) {
+	return i.weight
+}
+
+// Enabled reports whether the item takes part in the rotation.
+func (i Item) Enabled() (enabled bool) {
+	return i.enabled
+}
+
 // ItemInterface defines the interface that an Item must implement. This ensures that all items
 // can return their underlying value.
 type ItemInterface interface {
@@ -27,6 +79,99 @@ type ItemInterface interface {
 	Value() (value string)
 }
 
+// ItemHandle is a lightweight, long-lived reference to an item of a round-robin. Unlike Item, it does not carry a
+// copy of the item's state; instead it resolves the current state from the round-robin on demand. A handle only
+// resolves to the item it was created for: once that item is removed, the handle stays invalid even if an item
+// with the same value is added again. The zero ItemHandle is never valid.
+type ItemHandle struct {
+	// value is the value of the referenced item.
+	value string
+	// generation is the generation of the referenced item.
+	generation uint64
+	// rr is the round-robin the referenced item belongs to.
+	rr *RoundRobin
+}
+
+// Value returns the value of the referenced item.
+func (h ItemHandle) Value() (value string) {
+	return h.value
+}
+
+// Serves returns the current serve count of the referenced item. It returns ErrItemNotFound if the item is no
+// longer part of the round-robin.
+func (h ItemHandle) Serves() (serves int32, err error) {
+	if h.rr == nil {
+		err = ErrItemNotFound
+
+		return
+	}
+
+	h.rr.mutex.Lock()
+
+	defer h.rr.mutex.Unlock()
+
+	index := h.index()
+	if index < 0 {
+		err = ErrItemNotFound
+
+		return
+	}
+
+	serves = atomic.LoadInt32(&h.rr.items[index].Statistics.ServesCount)
+
+	return
+}
+
+// Valid reports whether the referenced item is still part of the round-robin.
+func (h ItemHandle) Valid() (valid bool) {
+	if h.rr == nil {
+		return
+	}
+
+	h.rr.mutex.Lock()
+
+	defer h.rr.mutex.Unlock()
+
+	return h.index() >= 0
+}
+
+// ReportFailure reports that work done with the referenced item failed, benching it for the duration cooldown
+// like RoundRobin.Cooldown. It returns ErrItemNotFound if the item is no longer part of the round-robin, so late
+// reports never bench an item that replaced it.
+func (h ItemHandle) ReportFailure(cooldown time.Duration) (err error) {
+	if h.rr == nil {
+		err = ErrItemNotFound
+
+		return
+	}
+
+	h.rr.mutex.Lock()
+
+	defer h.rr.mutex.Unlock()
+
+	index := h.index()
+	if index < 0 {
+		err = ErrItemNotFound
+
+		return
+	}
+
+	h.rr.cooldown(index, cooldown)
+
+	return
+}
+
+// index returns the position of the referenced item in the items slice, or -1 if it is no longer part of the
+// round-robin. It must be called with the mutex of the round-robin held.
+func (h ItemHandle) index() (index int) {
+	index = h.rr.indexOf(h.value)
+	if index >= 0 && h.rr.items[index].generation != h.generation {
+		return -1
+	}
+
+	return
+}
+
 // Statistics holds metrics related to an item, particularly how many times it has been served.
 // This allows for tracking and potentially balancing the distribution of items.
 type Statistics struct {
@@ -62,125 +207,1788 @@ type RoundRobin struct {
 	items []Item
 	// itemsMap is used in conjunction with the slice to ensure uniqueness of items.
 	itemsMap sync.Map
-	// nextItemIndex is the index of the next item to serve, managed atomically to support concurrent access.
+	// nextItemIndex is the index of the next item to serve, guarded by the mutex.
 	nextItemIndex uint32
 	// currentItemServesCount tracks the serve count of the currently serving item, allowing for rotation based on serve count.
 	currentItemServesCount uint32
+	// generations counts the items created, giving each its generation.
+	generations uint64
+	// histories holds the recent events of each item, when history tracking is enabled.
+	histories map[string]*historyRing
+	// weighted reports whether any item has a weight other than 1, switching rotation to weighted selection.
+	weighted bool
 	// mutex ensures thread-safe access to the round-robin, particularly for operations that modify its state.
 	mutex sync.Mutex
-	// Options hold configuration settings for the round-robin, like rotation behavior.
+	// tickets hands out arrival tickets to calls to Next, used to order them in serialized mode.
+	tickets atomic.Uint64
+	// servedTickets counts the ticketed calls that have been served, guarded by the mutex.
+	servedTickets uint64
+	// turn signals waiting ticketed calls that the next ticket may be served, created on first use.
+	turn *sync.Cond
+	// quotaServes holds the times of the serves in the current quota window, oldest first.
+	quotaServes []time.Time
+	// events holds the serves and rotations to report to the callbacks once the mutex is released.
+	events []serveEvent
+	// selectState is reused to describe the rotation to the strategy on every selection, avoiding allocations.
+	selectState SelectState
+	// contentionWaits counts the acquisitions of the mutex in Next that had to wait, when contention is tracked.
+	contentionWaits uint64
+	// contentionWait accumulates the time spent waiting for the mutex in Next, when contention is tracked.
+	contentionWait time.Duration
+	// id identifies the round-robin for ordering the locks of a transaction. It is assigned on first use.
+	id atomic.Uint64
+	// trackContention caches Options.TrackContention, so lock can check it without reading the options while the
+	// mutex is contended.
+	trackContention atomic.Bool
+	// Options hold configuration settings for the round-robin, like rotation behavior. Use CurrentOptions to read
+	// them while other goroutines may update them.
 	Options Options
 }
 
 // Items returns a copy of the items slice, allowing external access to the current state of the round-robin
-// without compromising thread safety.
+// without compromising thread safety. It takes the mutex, so it never observes membership changes of a Transact
+// call that has not committed yet.
 func (r *RoundRobin) Items() (items []Item) {
-	return r.items
+	r.mutex.Lock()
+
+	defer r.mutex.Unlock()
+
+	return slices.Clone(r.items)
+}
+
+// Len returns the number of items in the round-robin.
+func (r *RoundRobin) Len() (length int) {
+	r.mutex.Lock()
+
+	defer r.mutex.Unlock()
+
+	return len(r.items)
+}
+
+// Contains reports whether an item with the given value is part of the round-robin. The lookup consults the map
+// of values directly, without copying the items. It takes the mutex, so it never observes membership changes of a
+// Transact call that has not committed yet.
+func (r *RoundRobin) Contains(value string) (ok bool) {
+	r.mutex.Lock()
+
+	defer r.mutex.Unlock()
+
+	_, ok = r.itemsMap.Load(value)
+
+	return
 }
 
 // Add inserts one or more new values into the round-robin collection. It ensures that each item is unique
 // and updates the collection in a thread-safe manner.
 func (r *RoundRobin) Add(values ...string) {
+	r.mutex.Lock()
+
+	defer r.mutex.Unlock()
+
+	r.add(values...)
+}
+
+// add appends the values that are not yet present to the items slice. It must be called with the mutex held.
+func (r *RoundRobin) add(values ...string) {
 	for _, value := range values {
-		item := Item{
-			value: value,
-		}
+		r.addWeighted(value, 1)
+	}
+}
 
-		// Attempt to store the item in the map. If it's a new item, also append it to the slice.
-		if _, loaded := r.itemsMap.LoadOrStore(value, struct{}{}); !loaded {
-			r.items = append(r.items, item)
-		}
+// AddWeighted inserts a new value with the given weight into the round-robin collection, ignoring it if the
+// value is already present. Over a full cycle, items are served in proportion to their weights, using smooth
+// weighted round-robin selection so that the serves of heavier items are spread out rather than clumped
+// together. Items added with Add have a weight of 1, and weights below 1 are treated as 1.
+func (r *RoundRobin) AddWeighted(value string, weight int) {
+	r.mutex.Lock()
+
+	defer r.mutex.Unlock()
+
+	r.addWeighted(value, weight)
+}
+
+// AddWeightedBulk inserts each value of weights with its weight, like AddWeighted, under a single acquisition of
+// the mutex. Values already present are ignored, and new values are added in sorted order, keeping the rotation
+// deterministic.
+func (r *RoundRobin) AddWeightedBulk(weights map[string]int) {
+	r.mutex.Lock()
+
+	defer r.mutex.Unlock()
+
+	for _, value := range slices.Sorted(maps.Keys(weights)) {
+		r.addWeighted(value, weights[value])
 	}
 }
 
-// Next retrieves the next item in the round-robin order. It manages the serve count and rotates to the next item
-// as necessary, ensuring thread-safe access and modification of the round-robin state.
-func (r *RoundRobin) Next() (item Item) {
+// AddWithMaxServes inserts a new value into the round-robin collection that drops out of the rotation once its
+// serve count reaches maxServes, ignoring it if the value is already present. Exhausted items stay part of the
+// round-robin but are never served again, unless their serve count is lowered by Reset or SetStats. A maxServes
+// below 1 means no limit.
+func (r *RoundRobin) AddWithMaxServes(value string, maxServes int32) {
+	r.mutex.Lock()
+
+	defer r.mutex.Unlock()
+
+	if r.addWeighted(value, 1) {
+		r.items[len(r.items)-1].maxServes = max(maxServes, 0)
+	}
+}
+
+// AddWithRotate inserts a new value into the round-robin collection that is served rotate consecutive times
+// before the rotation advances, instead of Options.RotateAmount, ignoring it if the value is already present.
+// A rotate amount below 1 falls back to Options.RotateAmount.
+func (r *RoundRobin) AddWithRotate(value string, rotate int32) {
 	r.mutex.Lock()
 
 	defer r.mutex.Unlock()
 
-	currentAmount := atomic.LoadUint32(&r.currentItemServesCount)
+	if r.addWeighted(value, 1) {
+		r.items[len(r.items)-1].rotateAmount = max(rotate, 0)
+	}
+}
+
+// addWeighted appends the value with the given weight to the items slice if it is not yet present, reporting
+// whether it was added. It must be called with the mutex held.
+func (r *RoundRobin) addWeighted(value string, weight int) (added bool) {
+	item := r.newItem(value, weight)
 
-	// Rotate to the next item if the current item has reached its serve limit.
-	if currentAmount >= uint32(r.Options.RotateAmount) {
-		atomic.StoreUint32(&r.currentItemServesCount, 1)
-		atomic.AddUint32(&r.nextItemIndex, 1)
-	} else {
-		atomic.AddUint32(&r.currentItemServesCount, 1)
+	// Attempt to store the item in the map. If it's a new item, also append it to the slice.
+	if _, loaded := r.itemsMap.LoadOrStore(value, struct{}{}); loaded {
+		return
 	}
 
-	nextItemIndex := (int(r.nextItemIndex) - 1) % len(r.items)
+	r.items = append(r.items, item)
+
+	r.weighted = r.weighted || item.weight != 1
+
+	r.recordHistory(value, HistoryAdded, "weight="+strconv.Itoa(item.weight))
+
+	return true
+}
+
+// AddErr inserts one or more new values into the round-robin collection like Add. If Options.ErrorOnDuplicate
+// is set, it returns ErrDuplicateItem instead when any of the values is already present or repeated, and none
+// of the values is added.
+func (r *RoundRobin) AddErr(values ...string) (err error) {
+	r.mutex.Lock()
+
+	defer r.mutex.Unlock()
+
+	return r.addErr(values...)
+}
+
+// addErr inserts values like AddErr. It must be called with the mutex held.
+func (r *RoundRobin) addErr(values ...string) (err error) {
+	if r.Options.ErrorOnDuplicate {
+		seen := make(map[string]struct{}, len(values))
 
-	// Safeguard against index out-of-bounds, defaulting to the first item if necessary.
-	if nextItemIndex < 0 || nextItemIndex > len(r.items) {
-		r.items[0].Statistics.IncrementServesCount(1) // Increment stats by 1 everytime item is retrieved
+		for _, value := range values {
+			_, exists := r.itemsMap.Load(value)
+			_, repeated := seen[value]
 
-		return r.items[0]
+			if exists || repeated {
+				err = fmt.Errorf("%w: %s", ErrDuplicateItem, value)
+
+				return
+			}
+
+			seen[value] = struct{}{}
+		}
 	}
 
-	r.items[nextItemIndex].Statistics.IncrementServesCount(1)
+	r.add(values...)
 
-	return r.items[nextItemIndex]
+	return
 }
 
-// RoundRobinInterface defines the interface for a round-robin mechanism, abstracting the functionality
-// to add items and retrieve the next item in sequence. This facilitates testing and alternative implementations.
-type RoundRobinInterface interface {
-	// Items method retrieves a copy of the items  in the round-robin sequence.
-	Items() (items []Item)
-	// Add method allows adding one or more items to the round-robin.
-	Add(values ...string)
-	// Next method retrieves the next item in the round-robin sequence.
-	Next() (item Item)
+// GetOrAdd adds value to the round-robin if it is not already present and then serves the next item, which
+// is not necessarily the given value. Both steps happen under a single lock, so concurrent callers registering
+// the same value on demand neither race nor create duplicates.
+func (r *RoundRobin) GetOrAdd(value string) (item Item) {
+	r.lockTurn()
+
+	defer r.unlockTurn()
+
+	r.add(value)
+
+	item, _ = r.next()
+
+	return
 }
 
-// Options holds configuration settings for the round-robin, such as rotation amount.
-// This allows customization of the round-robin behavior.
-type Options struct {
-	// RotateAmount specifies the number of serves before rotating to the next item.
-	RotateAmount int32
+// Values returns a copy of the item values in rotation order. It is a lightweight alternative to Items for
+// callers that only need to know which values are in the round-robin.
+func (r *RoundRobin) Values() (values []string) {
+	r.mutex.Lock()
+
+	defer r.mutex.Unlock()
+
+	values = make([]string, len(r.items))
+
+	for i := range r.items {
+		values[i] = r.items[i].value
+	}
+
+	return
 }
 
-var (
-	// ErrNoItems indicates that no items are available for operation, typically used when initializing
-	// a new RoundRobin instance without any items.
-	ErrNoItems = errors.New("no items")
+// SetValues replaces the items in the round-robin with the given values, in the given order. Items whose values
+// are retained keep their statistics, and the rotation continues from the current item if it is retained. It
+// returns ErrNoItems if no values are provided.
+func (r *RoundRobin) SetValues(values []string) (err error) {
+	if len(values) == 0 {
+		err = ErrNoItems
 
-	// Interface assertions verify at compile time that the types implement the specified interfaces.
-	_ ItemInterface       = (*Item)(nil)
-	_ StatisticsInterface = (*Statistics)(nil)
-	_ RoundRobinInterface = (*RoundRobin)(nil)
+		return
+	}
 
-	// DefaultOptions provides a set of default configuration options for new round-robin instances,
-	// simplifying the initialization process.
-	DefaultOptions = Options{
-		RotateAmount: 1,
+	r.mutex.Lock()
+
+	defer r.mutex.Unlock()
+
+	r.setValues(values)
+
+	return
+}
+
+// setValues replaces the items in the round-robin with the given values like SetValues. It must be called with
+// the mutex held.
+func (r *RoundRobin) setValues(values []string) {
+	current := r.currentValue()
+
+	existing := make(map[string]Item, len(r.items))
+
+	for _, item := range r.items {
+		existing[item.value] = item
+	}
+
+	items := make([]Item, 0, len(values))
+
+	seen := make(map[string]struct{}, len(values))
+
+	for _, value := range values {
+		if _, ok := seen[value]; ok {
+			continue
+		}
+
+		seen[value] = struct{}{}
+
+		item, ok := existing[value]
+		if !ok {
+			item = r.newItem(value, 1)
+		}
+
+		items = append(items, item)
+	}
+
+	r.items = items
+
+	r.syncItemsMap()
+	r.refreshWeighted()
+	r.pruneHistories()
+
+	index := r.indexOf(current)
+	if index < 0 {
+		r.nextItemIndex = 1
+		r.currentItemServesCount = 0
+
+		return
+	}
+
+	r.nextItemIndex = uint32(index) + 1
+}
+
+// syncItemsMap updates the map of values to hold exactly the values of the items after they were replaced. New
+// values are stored before removed ones are deleted and retained values are never touched, so concurrent
+// lookups of the map never miss a retained value. It must be called with the mutex held.
+func (r *RoundRobin) syncItemsMap() {
+	values := make(map[string]struct{}, len(r.items))
+
+	for i := range r.items {
+		values[r.items[i].value] = struct{}{}
+
+		r.itemsMap.LoadOrStore(r.items[i].value, struct{}{})
 	}
+
+	r.itemsMap.Range(func(key, _ any) bool {
+		value, _ := key.(string)
+
+		if _, ok := values[value]; !ok {
+			r.itemsMap.Delete(key)
+		}
+
+		return true
+	})
+}
+
+// Action is the decision a MutateEach callback makes for an item.
+type Action int
+
+const (
+	// ActionKeep leaves the item as it is.
+	ActionKeep Action = iota
+	// ActionRemove removes the item from the round-robin.
+	ActionRemove
+	// ActionDisable takes the item out of the rotation, like Disable.
+	ActionDisable
 )
 
-// New creates a new RoundRobin instance with default options. It initializes the round-robin with a set of initial items,
-// returning an error if no items are provided.
-func New(items ...string) (rr *RoundRobin, err error) {
-	return NewWithOptions(DefaultOptions, items...)
+// MutateEach calls fn for each item in rotation order and applies the returned actions once every item has been
+// visited, all under a single acquisition of the mutex. It allows removing or disabling items matching a
+// condition in one pass; fn must not call methods of the round-robin, as the mutex is held.
+func (r *RoundRobin) MutateEach(fn func(item Item) (action Action)) {
+	r.mutex.Lock()
+
+	defer r.mutex.Unlock()
+
+	actions := make([]Action, len(r.items))
+
+	removed := false
+
+	for i := range r.items {
+		actions[i] = fn(r.items[i])
+
+		removed = removed || actions[i] == ActionRemove
+	}
+
+	values := make([]string, 0, len(r.items))
+
+	for i := range r.items {
+		switch actions[i] {
+		case ActionRemove:
+			continue
+		case ActionDisable:
+			if r.items[i].enabled {
+				r.items[i].enabled = false
+
+				r.recordHistory(r.items[i].value, HistoryDisabled, "")
+			}
+		case ActionKeep:
+		}
+
+		values = append(values, r.items[i].value)
+	}
+
+	if removed {
+		r.setValues(values)
+	}
 }
 
-// NewWithOptions creates a new RoundRobin instance with custom options. It allows for greater flexibility
-// in configuring the round-robin behavior and initializes the instance with a set of initial items.
-func NewWithOptions(options Options, items ...string) (rr *RoundRobin, err error) {
-	if len(items) == 0 {
-		err = ErrNoItems
+// Next retrieves the next item in the round-robin order. It manages the serve count and rotates to the next item
+// as necessary, ensuring thread-safe access and modification of the round-robin state.
+//
+// Next returns a zero Item, whose Value is empty, if the round-robin has no items. Use NextE to tell that case
+// apart from a served item.
+func (r *RoundRobin) Next() (item Item) {
+	item, _ = r.NextE()
+
+	return
+}
+
+// NextE retrieves the next item in the round-robin order like Next, but returns ErrNoItems instead of a zero
+// Item if the round-robin has no items, and ErrQuotaExceeded if the quota of the current window is used up.
+func (r *RoundRobin) NextE() (item Item, err error) {
+	_, item, err = r.nextTicketed()
+
+	return
+}
+
+// Iter returns an iterator over the next n items in round-robin order. Each item is served like Next when it is
+// yielded, with the mutex only held while serving it, so stopping early serves no further items and the loop body
+// may use the round-robin. The iterator stops early if no item can be served.
+func (r *RoundRobin) Iter(n int) iter.Seq[Item] {
+	return func(yield func(item Item) bool) {
+		for range n {
+			item, err := r.NextE()
+			if err != nil || !yield(item) {
+				return
+			}
+		}
+	}
+}
+
+// NextInfo serves the next item like Next and also reports whether the call advanced the rotation from the item
+// served by the previous call to a different one, exactly when Options.OnRotate is called. Repeating the current
+// item, under a RotateAmount above 1 or because it is picked again, and the very first serve do not count as
+// rotations. It returns a zero Item and false if no item can be served.
+func (r *RoundRobin) NextInfo() (item Item, rotated bool) {
+	r.lockTurn()
 
+	defer r.unlockTurn()
+
+	item, rotated, _ = r.nextAdvanced()
+
+	return
+}
+
+// NextRanked serves the next item like Next and also returns the rank it had among the eligible items by the
+// selection criterion of the strategy in use, 1 being the most preferred. Strategies that do not implement Ranker,
+// like StrategyRoundRobin where the served item simply was next, always rank it 1. It returns a zero Item and
+// rank 0 if no item can be served.
+func (r *RoundRobin) NextRanked() (item Item, rank int) {
+	r.lockTurn()
+
+	defer r.unlockTurn()
+
+	if r.quotaExceeded() {
 		return
 	}
 
-	rr = &RoundRobin{
-		Options: options,
+	index, rotated, ok := r.selectIndex()
+	if !ok {
+		return
 	}
 
-	rr.Add(items...)
-	// Ensure the next item index starts from the first item.
-	rr.nextItemIndex = 1
+	rank = 1
+
+	if ranker, ok := r.strategy().(Ranker); ok {
+		rank = ranker.Rank(r.items, &r.selectState, index)
+	}
+
+	item, _ = r.serveSelected(index, rotated)
 
 	return
 }
+
+// NextN retrieves the next n items in round-robin order, exactly as if Next were called n times, wrapping around
+// the items as often as needed. The mutex is acquired once for the whole batch. It returns an empty slice if n
+// is not positive or the round-robin has no items.
+func (r *RoundRobin) NextN(n int) (items []Item) {
+	items = make([]Item, 0, max(n, 0))
+
+	r.lockTurn()
+
+	defer r.unlockTurn()
+
+	for range n {
+		item, err := r.next()
+		if err != nil {
+			break
+		}
+
+		items = append(items, item)
+	}
+
+	return
+}
+
+// NextTicket serves the next item like Next and also returns the ticket the call was assigned on arrival. With
+// Options.SerializedMode set, calls are served strictly in ticket order, so the item a ticket receives only
+// depends on the ticket and not on how concurrent callers are scheduled.
+func (r *RoundRobin) NextTicket() (ticket uint64, item Item) {
+	ticket, item, _ = r.nextTicketed()
+
+	return
+}
+
+// nextTicketed serves the next item on its turn, returning the ticket the call was assigned.
+func (r *RoundRobin) nextTicketed() (ticket uint64, item Item, err error) {
+	ticket = r.lockTurn()
+
+	defer r.unlockTurn()
+
+	item, err = r.next()
+
+	return
+}
+
+// lockTurn assigns the call a ticket and acquires the mutex, waiting for the ticket's turn if
+// Options.SerializedMode is set. Every call that serves an item or advances the rotation must acquire the mutex
+// through it, so that serialized serves are totally ordered. It must be paired with unlockTurn.
+func (r *RoundRobin) lockTurn() (ticket uint64) {
+	ticket = r.tickets.Add(1) - 1
+
+	r.lock()
+
+	if !r.Options.SerializedMode {
+		return
+	}
+
+	if r.turn == nil {
+		r.turn = sync.NewCond(&r.mutex)
+	}
+
+	for r.servedTickets < ticket {
+		r.turn.Wait()
+	}
+
+	return
+}
+
+// unlockTurn marks the current ticket as served, lets the next ticket take its turn and releases the mutex.
+func (r *RoundRobin) unlockTurn() {
+	r.servedTickets++
+
+	if r.turn != nil {
+		r.turn.Broadcast()
+	}
+
+	r.unlock()
+}
+
+// Peek returns the item the next call to Next would serve, without serving it. Neither the rotation position
+// nor the statistics of any item change. It returns a zero Item if the round-robin has no eligible items.
+func (r *RoundRobin) Peek() (item Item) {
+	r.mutex.Lock()
+
+	defer r.mutex.Unlock()
+
+	if index, ok := r.peekIndex(); ok {
+		item = r.items[index]
+	}
+
+	return
+}
+
+// next advances the rotation and serves the next item, returning ErrNoItems if there are no eligible items and
+// ErrQuotaExceeded if the quota of the current window is used up. It must be called with the mutex held.
+func (r *RoundRobin) next() (item Item, err error) {
+	item, _, err = r.nextAdvanced()
+
+	return
+}
+
+// nextAdvanced serves the next item like next and also reports whether the rotation advanced from the item served
+// before to a different one. It must be called with the mutex held.
+func (r *RoundRobin) nextAdvanced() (item Item, advanced bool, err error) {
+	if r.quotaExceeded() {
+		err = ErrQuotaExceeded
+
+		return
+	}
+
+	nextItemIndex, rotated, ok := r.selectIndex()
+	if !ok {
+		err = ErrNoItems
+
+		return
+	}
+
+	item, advanced = r.serveSelected(nextItemIndex, rotated)
+
+	return
+}
+
+// serveSelected serves the item at the given index picked by selectIndex, starting a new turn for it if rotated.
+// It reports whether the rotation advanced from the item served before to a different one. It must be called with
+// the mutex held.
+func (r *RoundRobin) serveSelected(nextItemIndex int, rotated bool) (item Item, advanced bool) {
+	if rotated {
+		if index := int(r.nextItemIndex) - 1; index >= 0 && index < len(r.items) && index != nextItemIndex && r.currentItemServesCount > 0 {
+			advanced = true
+
+			r.rotatedTo(index, nextItemIndex)
+		}
+
+		r.currentItemServesCount = 0
+
+		if r.weighted {
+			r.applyWeightedSelection(nextItemIndex)
+		}
+	}
+
+	r.nextItemIndex = uint32(nextItemIndex) + 1
+	r.currentItemServesCount++
+
+	// Increment stats by 1 everytime item is retrieved, unless counting is deferred until completion.
+	r.countServe(nextItemIndex)
+
+	r.served(nextItemIndex)
+
+	item = r.items[nextItemIndex]
+
+	return
+}
+
+// selectIndex returns the index of the item the next serve goes to, and whether serving it advances the
+// rotation to a new item. Ineligible items are skipped, and ok is false if no item is eligible. It does not
+// change any state and must be called with the mutex held.
+func (r *RoundRobin) selectIndex() (index int, rotated, ok bool) {
+	return r.selectIndexWith(r.strategy())
+}
+
+// peekIndex returns the index of the item the next serve goes to like selectIndex, selecting with a clone of a
+// stateful strategy so that peeking does not change the choices of the following serves. It must be called with
+// the mutex held.
+func (r *RoundRobin) peekIndex() (index int, ok bool) {
+	strategy := r.strategy()
+
+	if cloner, isCloner := strategy.(Cloner); isCloner {
+		strategy = cloner.CloneStrategy()
+	}
+
+	index, _, ok = r.selectIndexWith(strategy)
+
+	return
+}
+
+// selectIndexWith returns the index of the item the next serve goes to like selectIndex, using the given
+// strategy. It must be called with the mutex held.
+func (r *RoundRobin) selectIndexWith(strategy Strategy) (index int, rotated, ok bool) {
+	if len(r.items) == 0 {
+		return
+	}
+
+	// The rotation state is only accessed with the mutex held, so it needs no atomic operations. The cursor is
+	// stored as the 1-based position of the item being served, zero meaning that nothing has been served yet.
+	state := &r.selectState
+
+	state.Current = (int(r.nextItemIndex) - 1) % len(r.items)
+	state.Serves = int(r.currentItemServesCount)
+	state.RotateAmount = 0
+	state.Weighted = r.weighted
+
+	if state.Current >= 0 {
+		state.RotateAmount = int(r.rotateAmount(state.Current))
+	}
+
+	if state.Eligible == nil {
+		state.Eligible = r.eligible
+	}
+
+	index = strategy.Select(r.items, state)
+	if index < 0 || index >= len(r.items) || !r.eligible(index) {
+		return -1, true, false
+	}
+
+	rotated = index != state.Current || state.Serves >= state.RotateAmount || (state.Weighted && state.Serves == 0)
+
+	return index, rotated, true
+}
+
+// copyOptions returns a copy of the options for a copy of the round-robin, cloning a stateful strategy so that the
+// copies do not share its state. It must be called with the mutex held.
+func (r *RoundRobin) copyOptions() (options Options) {
+	options = r.Options
+
+	if cloner, ok := options.Strategy.(Cloner); ok {
+		options.Strategy = cloner.CloneStrategy()
+	}
+
+	return
+}
+
+// strategy returns the selection strategy in use: Options.Strategy, or StrategyRoundRobin if unset.
+func (r *RoundRobin) strategy() (strategy Strategy) {
+	if r.Options.Strategy != nil {
+		return r.Options.Strategy
+	}
+
+	return StrategyRoundRobin{}
+}
+
+// rotateAmount returns the number of consecutive serves the item at the given index gets before the rotation
+// advances: its own rotate amount if set, Options.RotateAmount otherwise, and at least 1. It must be called with
+// the mutex held.
+func (r *RoundRobin) rotateAmount(index int) (amount int32) {
+	if amount = r.items[index].rotateAmount; amount > 0 {
+		return
+	}
+
+	return max(r.Options.RotateAmount, 1)
+}
+
+// eligible reports whether the item at the given index may currently be served by the rotation. It must be
+// called with the mutex held.
+func (r *RoundRobin) eligible(index int) (ok bool) {
+	item := &r.items[index]
+
+	if !item.enabled {
+		return
+	}
+
+	if item.maxServes > 0 && atomic.LoadInt32(&item.Statistics.ServesCount) >= item.maxServes {
+		return
+	}
+
+	// Only consult the clock for items that have been benched, keeping the common path free of clock reads.
+	return item.cooldownUntil.IsZero() || !r.now().Before(item.cooldownUntil)
+}
+
+// now returns the current time from Options.Now, falling back to time.Now. It must be called with the mutex
+// held.
+func (r *RoundRobin) now() (now time.Time) {
+	if r.Options.Now != nil {
+		return r.Options.Now()
+	}
+
+	return time.Now()
+}
+
+// applyWeightedSelection updates the running weights after smooth weighted round-robin selection picked the item
+// at the given index: every eligible item gains its weight and the selected item gives up their total weight.
+// It must be called with the mutex held.
+func (r *RoundRobin) applyWeightedSelection(index int) {
+	total := 0
+
+	for i := range r.items {
+		if !r.eligible(i) {
+			continue
+		}
+
+		r.items[i].currentWeight += r.items[i].weight
+
+		total += r.items[i].weight
+	}
+
+	r.items[index].currentWeight -= total
+}
+
+// refreshWeighted recomputes whether any item has a weight other than 1, enabling weighted selection. It must be
+// called with the mutex held whenever items are replaced.
+func (r *RoundRobin) refreshWeighted() {
+	r.weighted = false
+
+	for i := range r.items {
+		if r.items[i].weight != 1 {
+			r.weighted = true
+
+			return
+		}
+	}
+}
+
+// NextAvailable retrieves the next item in the round-robin order like NextE, but if every item is currently
+// disabled or cooling down it blocks until one becomes eligible or ctx is done, returning ctx.Err() in the
+// latter case. Waiting is driven by the nearest cooldown deadline, polling periodically to also notice items
+// being enabled. It returns ErrNoItems right away if the round-robin has no items at all.
+func (r *RoundRobin) NextAvailable(ctx context.Context) (item Item, err error) {
+	for {
+		var wait time.Duration
+
+		item, wait, err = r.tryNext()
+		if err == nil || wait <= 0 {
+			return
+		}
+
+		timer := time.NewTimer(wait)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return Item{}, ctx.Err() //nolint:wrapcheck // The context error is returned as is, as documented.
+		case <-timer.C:
+		}
+	}
+}
+
+// tryNext serves the next item if any is eligible. Otherwise it returns ErrNoItems together with how long to wait
+// before trying again: until the nearest cooldown deadline, but no longer than availablePollInterval. The wait
+// is zero if there are no items to wait for.
+func (r *RoundRobin) tryNext() (item Item, wait time.Duration, err error) {
+	r.lockTurn()
+
+	defer r.unlockTurn()
+
+	if item, err = r.next(); err == nil || len(r.items) == 0 {
+		return
+	}
+
+	wait = availablePollInterval
+
+	if errors.Is(err, ErrQuotaExceeded) {
+		wait = min(wait, r.quotaRefill())
+	}
+
+	now := r.now()
+
+	for i := range r.items {
+		if r.items[i].enabled && r.items[i].cooldownUntil.After(now) {
+			wait = min(wait, r.items[i].cooldownUntil.Sub(now))
+		}
+	}
+
+	return
+}
+
+// NextValue serves the next item like Next, but returns only its value, for callers that only rotate through
+// values. It performs no allocations.
+func (r *RoundRobin) NextValue() (value string) {
+	_, item, _ := r.nextTicketed()
+
+	return item.value
+}
+
+// NextWithCompletion serves the next item like Next and returns a function to call once the work done with the
+// item has completed. If Options.CountOnComplete is set, the item's serve count is only incremented when
+// complete is called, so abandoned serves are not counted; otherwise complete does nothing. Calling complete
+// more than once has no further effect, and neither does calling it after the item was removed, even if an item
+// with the same value was added again since.
+func (r *RoundRobin) NextWithCompletion() (item Item, complete func()) {
+	r.lockTurn()
+
+	defer r.unlockTurn()
+
+	item, err := r.next()
+
+	if err != nil || !r.Options.CountOnComplete {
+		complete = func() {}
+
+		return
+	}
+
+	handle := ItemHandle{
+		value:      item.value,
+		generation: item.generation,
+		rr:         r,
+	}
+
+	complete = sync.OnceFunc(func() {
+		r.mutex.Lock()
+
+		defer r.mutex.Unlock()
+
+		if index := handle.index(); index >= 0 {
+			r.recordServe(index)
+		}
+	})
+
+	return
+}
+
+// NextPreferred serves the first value of prefer that is part of the round-robin and eligible, falling back to
+// Next if none of them is. A preferred item has its serve count incremented like any other serve, but the rotation
+// position is left untouched, so honoring preferences does not make the rotation skip items. It returns a zero
+// Item if the quota of the current window is used up.
+func (r *RoundRobin) NextPreferred(prefer []string) (item Item) {
+	r.lockTurn()
+
+	defer r.unlockTurn()
+
+	if r.quotaExceeded() {
+		return
+	}
+
+	for _, value := range prefer {
+		if index := r.indexOf(value); index >= 0 && r.eligible(index) {
+			r.countServe(index)
+			r.served(index)
+
+			return r.items[index]
+		}
+	}
+
+	item, _ = r.next()
+
+	return
+}
+
+// NextDistinctForKey serves an eligible item whose value is not in alreadyUsed, so that replicas of the given key
+// land on distinct items. Among the remaining items it picks the one ranked highest for the key by rendezvous
+// hashing, so the same key maps to the same items as long as they stay available. Like NextPreferred it leaves the
+// rotation position untouched. It returns ErrNoDistinctItems if every eligible item has already been used.
+func (r *RoundRobin) NextDistinctForKey(key string, alreadyUsed []string) (item Item, err error) {
+	r.lockTurn()
+
+	defer r.unlockTurn()
+
+	if r.quotaExceeded() {
+		err = ErrQuotaExceeded
+
+		return
+	}
+
+	best := -1
+
+	var bestScore uint64
+
+	for i := range r.items {
+		if !r.eligible(i) || slices.Contains(alreadyUsed, r.items[i].value) {
+			continue
+		}
+
+		if score := rendezvousScore(key, r.items[i].value); best < 0 || score > bestScore {
+			best, bestScore = i, score
+		}
+	}
+
+	if best < 0 {
+		err = ErrNoDistinctItems
+
+		return
+	}
+
+	r.countServe(best)
+	r.served(best)
+
+	item = r.items[best]
+
+	return
+}
+
+// rendezvousScore returns the rendezvous hashing score of value for key.
+func rendezvousScore(key, value string) (score uint64) {
+	hash := fnv.New64a()
+
+	_, _ = hash.Write([]byte(key))
+	_, _ = hash.Write([]byte{0})
+	_, _ = hash.Write([]byte(value))
+
+	return hash.Sum64()
+}
+
+// Disable takes the item with the given value out of the rotation without removing it: the item keeps its
+// statistics and position, but Next skips it until it is enabled again. It returns ErrItemNotFound if no such
+// item exists.
+func (r *RoundRobin) Disable(value string) (err error) {
+	return r.setEnabled(value, false)
+}
+
+// Enable returns the item with the given value to the rotation after a call to Disable. It returns
+// ErrItemNotFound if no such item exists.
+func (r *RoundRobin) Enable(value string) (err error) {
+	return r.setEnabled(value, true)
+}
+
+// AllEligible reports whether every item may currently be served, that is, none is disabled or cooling down.
+// It is meant for readiness checks that should fail as soon as any item is unavailable.
+func (r *RoundRobin) AllEligible() (ok bool) {
+	r.mutex.Lock()
+
+	defer r.mutex.Unlock()
+
+	for i := range r.items {
+		if !r.eligible(i) {
+			return
+		}
+	}
+
+	return true
+}
+
+// IneligibleValues returns the values of the items that may not currently be served, in rotation order. It is
+// empty exactly when AllEligible reports true.
+func (r *RoundRobin) IneligibleValues() (values []string) {
+	r.mutex.Lock()
+
+	defer r.mutex.Unlock()
+
+	values = []string{}
+
+	for i := range r.items {
+		if !r.eligible(i) {
+			values = append(values, r.items[i].value)
+		}
+	}
+
+	return
+}
+
+// EligibleItems returns a copy of the items that may currently be served, in rotation order: enabled items that
+// are neither cooling down nor exhausted.
+func (r *RoundRobin) EligibleItems() (items []Item) {
+	r.mutex.Lock()
+
+	defer r.mutex.Unlock()
+
+	items = []Item{}
+
+	for i := range r.items {
+		if r.eligible(i) {
+			items = append(items, r.items[i])
+		}
+	}
+
+	return
+}
+
+// EligibleLen returns the number of items that may currently be served, like len(EligibleItems()) but without
+// copying the items.
+func (r *RoundRobin) EligibleLen() (n int) {
+	r.mutex.Lock()
+
+	defer r.mutex.Unlock()
+
+	for i := range r.items {
+		if r.eligible(i) {
+			n++
+		}
+	}
+
+	return
+}
+
+// Cooldown benches the item with the given value for the duration d: Next skips it until the cooldown elapses,
+// after which it rejoins the rotation automatically. The cooldown is measured with Options.Now when set. It
+// returns ErrItemNotFound if no such item exists.
+func (r *RoundRobin) Cooldown(value string, d time.Duration) (err error) {
+	r.mutex.Lock()
+
+	defer r.mutex.Unlock()
+
+	index := r.indexOf(value)
+	if index < 0 {
+		err = ErrItemNotFound
+
+		return
+	}
+
+	r.cooldown(index, d)
+
+	return
+}
+
+// cooldown benches the item at the given index for the duration d. It must be called with the mutex held.
+func (r *RoundRobin) cooldown(index int, d time.Duration) {
+	r.items[index].cooldownUntil = r.now().Add(d)
+
+	r.recordHistory(r.items[index].value, HistoryCooldown, d.String())
+}
+
+// setEnabled sets whether the item with the given value takes part in the rotation.
+func (r *RoundRobin) setEnabled(value string, enabled bool) (err error) {
+	r.mutex.Lock()
+
+	defer r.mutex.Unlock()
+
+	index := r.indexOf(value)
+	if index < 0 {
+		err = ErrItemNotFound
+
+		return
+	}
+
+	r.items[index].enabled = enabled
+
+	event := HistoryDisabled
+
+	if enabled {
+		event = HistoryEnabled
+	}
+
+	r.recordHistory(value, event, "")
+
+	return
+}
+
+// Serve retrieves the item with the given value directly, bypassing the rotation. The item's serve count is
+// incremented, but the rotation position is left untouched. It returns ErrItemNotFound if no such item exists.
+func (r *RoundRobin) Serve(value string) (item Item, err error) {
+	r.lockTurn()
+
+	defer r.unlockTurn()
+
+	index := r.indexOf(value)
+	if index < 0 {
+		err = ErrItemNotFound
+
+		return
+	}
+
+	r.recordServe(index)
+	r.served(index)
+
+	item = r.items[index]
+
+	return
+}
+
+// MostServed returns the item with the highest serve count, preferring the earliest item on ties. The ok result
+// is false if the round-robin has no items.
+func (r *RoundRobin) MostServed() (item Item, ok bool) {
+	r.mutex.Lock()
+
+	defer r.mutex.Unlock()
+
+	return r.extremeServed(func(candidate, current int32) bool {
+		return candidate > current
+	})
+}
+
+// LeastServed returns the item with the lowest serve count, preferring the earliest item on ties. The ok result
+// is false if the round-robin has no items.
+func (r *RoundRobin) LeastServed() (item Item, ok bool) {
+	r.mutex.Lock()
+
+	defer r.mutex.Unlock()
+
+	return r.extremeServed(func(candidate, current int32) bool {
+		return candidate < current
+	})
+}
+
+// extremeServed scans the items and returns the one whose serve count is preferred by better over every other
+// item. It must be called with the mutex held.
+func (r *RoundRobin) extremeServed(better func(candidate, current int32) bool) (item Item, ok bool) {
+	if len(r.items) == 0 {
+		return
+	}
+
+	selected := 0
+
+	for i := 1; i < len(r.items); i++ {
+		if better(atomic.LoadInt32(&r.items[i].Statistics.ServesCount), atomic.LoadInt32(&r.items[selected].Statistics.ServesCount)) {
+			selected = i
+		}
+	}
+
+	return r.items[selected], true
+}
+
+// Stats returns an iterator over the value and serve count of each item, in rotation order. The mutex is held
+// for the duration of the iteration, so the loop body must not call other methods of the round-robin; breaking
+// out of the loop releases it.
+func (r *RoundRobin) Stats() iter.Seq2[string, int32] {
+	return func(yield func(value string, serves int32) bool) {
+		r.mutex.Lock()
+
+		defer r.mutex.Unlock()
+
+		for i := range r.items {
+			if !yield(r.items[i].value, atomic.LoadInt32(&r.items[i].Statistics.ServesCount)) {
+				return
+			}
+		}
+	}
+}
+
+// StatsMap returns a snapshot mapping each item value to its serve count. The map is a copy that the caller may
+// read and modify freely without affecting the round-robin.
+func (r *RoundRobin) StatsMap() (stats map[string]int32) {
+	r.mutex.Lock()
+
+	defer r.mutex.Unlock()
+
+	stats = make(map[string]int32, len(r.items))
+
+	for i := range r.items {
+		stats[r.items[i].value] = atomic.LoadInt32(&r.items[i].Statistics.ServesCount)
+	}
+
+	return
+}
+
+// SetStats replaces the serve counts of the items with the given ones, for reconciling with an authoritative
+// source. Items missing from stats are reset to zero. Values in stats that are not part of the round-robin are
+// ignored, unless Options.ErrorOnUnknownStats is set, in which case ErrItemNotFound is returned and no serve
+// count is changed.
+func (r *RoundRobin) SetStats(stats map[string]int32) (err error) {
+	r.mutex.Lock()
+
+	defer r.mutex.Unlock()
+
+	if r.Options.ErrorOnUnknownStats {
+		for value := range stats {
+			if _, ok := r.itemsMap.Load(value); !ok {
+				err = fmt.Errorf("%w: %s", ErrItemNotFound, value)
+
+				return
+			}
+		}
+	}
+
+	for i := range r.items {
+		atomic.StoreInt32(&r.items[i].Statistics.ServesCount, stats[r.items[i].value])
+	}
+
+	return
+}
+
+// ServeHistogram summarizes the distribution of serve counts: it splits the range between the lowest and the
+// highest serve count into the given number of evenly spaced buckets and returns how many items fall into each,
+// the highest count belonging to the last bucket. If all items have the same serve count, they all fall into the
+// first bucket. It returns nil if buckets is not positive or the round-robin has no items.
+func (r *RoundRobin) ServeHistogram(buckets int) (histogram []int) {
+	r.mutex.Lock()
+
+	defer r.mutex.Unlock()
+
+	if buckets < 1 || len(r.items) == 0 {
+		return
+	}
+
+	least, most := int64(math.MaxInt32), int64(math.MinInt32)
+
+	for i := range r.items {
+		serves := int64(atomic.LoadInt32(&r.items[i].Statistics.ServesCount))
+
+		least, most = min(least, serves), max(most, serves)
+	}
+
+	histogram = make([]int, buckets)
+
+	for i := range r.items {
+		bucket := 0
+
+		if most > least {
+			serves := int64(atomic.LoadInt32(&r.items[i].Statistics.ServesCount))
+
+			bucket = min(int((serves-least)*int64(buckets)/(most-least)), buckets-1)
+		}
+
+		histogram[bucket]++
+	}
+
+	return
+}
+
+// TotalServes returns the sum of the serve counts of all items.
+func (r *RoundRobin) TotalServes() (total int64) {
+	r.mutex.Lock()
+
+	defer r.mutex.Unlock()
+
+	for i := range r.items {
+		total += int64(atomic.LoadInt32(&r.items[i].Statistics.ServesCount))
+	}
+
+	return
+}
+
+// FullReset restarts the rotation from the first item and resets the statistics of every item in a single
+// locked operation, so no concurrent serve can observe or land between the two resets.
+func (r *RoundRobin) FullReset() {
+	r.mutex.Lock()
+
+	defer r.mutex.Unlock()
+
+	r.reset()
+}
+
+// Reset is equivalent to FullReset.
+func (r *RoundRobin) Reset() {
+	r.FullReset()
+}
+
+// reset restarts the rotation from the first item and resets the statistics and running weights of every item.
+// It must be called with the mutex held.
+func (r *RoundRobin) reset() {
+	r.nextItemIndex = 1
+	r.currentItemServesCount = 0
+
+	for i := range r.items {
+		r.items[i].Statistics.ResetServesCount()
+
+		r.items[i].currentWeight = 0
+	}
+}
+
+// PauseStats stops serve counts from being incremented while items keep being served and rotated, which is
+// useful for serves that should not count towards long-term statistics, such as synthetic probes.
+func (r *RoundRobin) PauseStats() {
+	r.mutex.Lock()
+
+	defer r.mutex.Unlock()
+
+	r.Options.StatsPaused = true
+	r.Options.version++
+}
+
+// ResumeStats resumes incrementing serve counts after a call to PauseStats.
+func (r *RoundRobin) ResumeStats() {
+	r.mutex.Lock()
+
+	defer r.mutex.Unlock()
+
+	r.Options.StatsPaused = false
+	r.Options.version++
+}
+
+// countServe records a serve selected by the round-robin for the item at the given index, unless
+// Options.CountOnComplete defers counting until completion. It must be called with the mutex held.
+func (r *RoundRobin) countServe(index int) {
+	if !r.Options.CountOnComplete {
+		r.recordServe(index)
+	}
+}
+
+// recordServe increments the serve count of the item at the given index, unless statistics are paused.
+// It must be called with the mutex held.
+func (r *RoundRobin) recordServe(index int) {
+	if r.Options.StatsPaused {
+		return
+	}
+
+	r.items[index].Statistics.IncrementServesCount(1)
+}
+
+// ContentionStats returns how many times serving calls, such as Next, NextPreferred or Serve, and GetOrAdd had to
+// wait to acquire the mutex, and the total time spent waiting. The counters are only updated while contention
+// tracking is enabled by Options.TrackContention.
+func (r *RoundRobin) ContentionStats() (waits uint64, totalWait time.Duration) {
+	r.mutex.Lock()
+
+	defer r.mutex.Unlock()
+
+	return r.contentionWaits, r.contentionWait
+}
+
+// lock acquires the mutex, recording the time spent waiting for it if contention tracking is enabled. The wait
+// is only timed when the mutex is not immediately available and tracking is enabled, keeping the other paths
+// free of clock reads.
+func (r *RoundRobin) lock() {
+	if r.mutex.TryLock() {
+		return
+	}
+
+	if !r.trackContention.Load() {
+		r.mutex.Lock()
+
+		return
+	}
+
+	start := time.Now()
+
+	r.mutex.Lock()
+
+	r.contentionWaits++
+	r.contentionWait += time.Since(start)
+}
+
+// currentValue returns the value of the item under the rotation cursor, or an empty string if there is none.
+// It must be called with the mutex held.
+func (r *RoundRobin) currentValue() (value string) {
+	if len(r.items) == 0 || r.nextItemIndex == 0 {
+		return
+	}
+
+	return r.items[(int(r.nextItemIndex)-1)%len(r.items)].value
+}
+
+// indexOf returns the position of the item with the given value in the items slice, or -1 if it is not present.
+// It must be called with the mutex held.
+func (r *RoundRobin) indexOf(value string) (index int) {
+	for i := range r.items {
+		if r.items[i].value == value {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// Sort orders the items using less, keeping the order of equal items. The rotation position follows the item
+// currently being served, so serving continues with the same item and then with its successor in the new order.
+func (r *RoundRobin) Sort(less func(a, b Item) bool) {
+	r.mutex.Lock()
+
+	defer r.mutex.Unlock()
+
+	current := r.currentValue()
+
+	slices.SortStableFunc(r.items, func(a, b Item) int {
+		switch {
+		case less(a, b):
+			return -1
+		case less(b, a):
+			return 1
+		default:
+			return 0
+		}
+	})
+
+	if index := r.indexOf(current); index >= 0 {
+		r.nextItemIndex = uint32(index) + 1
+	}
+}
+
+// SeededShuffle deterministically permutes the items from the given seed and restarts the rotation from the
+// first item. The items are ordered by value before being shuffled, so round-robins holding the same values
+// produce the same order for the same seed regardless of the order in which the values were added.
+func (r *RoundRobin) SeededShuffle(seed int64) {
+	r.mutex.Lock()
+
+	defer r.mutex.Unlock()
+
+	slices.SortFunc(r.items, func(a, b Item) int {
+		return strings.Compare(a.value, b.value)
+	})
+
+	random := rand.New(rand.NewPCG(uint64(seed), 0))
+
+	random.Shuffle(len(r.items), func(i, j int) {
+		r.items[i], r.items[j] = r.items[j], r.items[i]
+	})
+
+	r.nextItemIndex = 1
+	r.currentItemServesCount = 0
+}
+
+// NextHandle serves the next item like Next, but returns a lightweight handle to it instead of a copy of the item.
+func (r *RoundRobin) NextHandle() (handle ItemHandle) {
+	item := r.Next()
+
+	return ItemHandle{
+		value:      item.value,
+		generation: item.generation,
+		rr:         r,
+	}
+}
+
+// Trace returns the values of the next n items that Next would serve, in order. The serves are simulated on a
+// copy of the round-robin, so neither the rotation position nor the statistics of the round-robin change.
+func (r *RoundRobin) Trace(n int) (values []string) {
+	r.mutex.Lock()
+
+	clone := r.clone()
+
+	r.mutex.Unlock()
+
+	return clone.trace(n)
+}
+
+// EffectiveOrder returns the values Next would serve over exactly one full cycle, starting from the current
+// rotation position. Like Trace, it simulates the serves on a copy of the round-robin without changing it.
+func (r *RoundRobin) EffectiveOrder() (values []string) {
+	r.mutex.Lock()
+
+	clone := r.clone()
+
+	r.mutex.Unlock()
+
+	return clone.trace(clone.cycleLength())
+}
+
+// trace serves n items and returns their values in order. It is meant to be called on a clone.
+func (r *RoundRobin) trace(n int) (values []string) {
+	values = make([]string, 0, max(n, 0))
+
+	for range n {
+		item, err := r.next()
+		if err != nil {
+			return
+		}
+
+		values = append(values, item.value)
+	}
+
+	return
+}
+
+// cycleLength returns the number of serves it takes to serve every eligible item for a full turn. It must be
+// called with the mutex held.
+func (r *RoundRobin) cycleLength() (length int) {
+	for i := range r.items {
+		if r.eligible(i) {
+			length += r.items[i].weight * int(r.rotateAmount(i))
+		}
+	}
+
+	return
+}
+
+// SubPool returns a new, independent round-robin containing the items that match pred, in their current order
+// and with their statistics. The sub-pool inherits the options of the round-robin but rotates on its own,
+// starting from its first item. It returns ErrNoItems if no item matches.
+func (r *RoundRobin) SubPool(pred func(item Item) bool) (rr *RoundRobin, err error) {
+	r.mutex.Lock()
+
+	defer r.mutex.Unlock()
+
+	rr = &RoundRobin{
+		nextItemIndex: 1,
+		generations:   r.generations,
+	}
+
+	rr.setOptions(r.copyOptions())
+
+	for _, item := range r.items {
+		if !pred(item) {
+			continue
+		}
+
+		rr.items = append(rr.items, item)
+
+		rr.itemsMap.Store(item.value, struct{}{})
+	}
+
+	if len(rr.items) == 0 {
+		rr, err = nil, ErrNoItems
+
+		return
+	}
+
+	rr.refreshWeighted()
+
+	return
+}
+
+// String returns a compact human-readable summary of the round-robin, such as
+// "RoundRobin(3 items, next=item2, rotateAmount=1, serves=[item1:1 item2:0 item3:0])", for use in logs.
+func (r *RoundRobin) String() (summary string) {
+	r.mutex.Lock()
+
+	defer r.mutex.Unlock()
+
+	next := "<none>"
+
+	if index, ok := r.peekIndex(); ok {
+		next = r.items[index].value
+	}
+
+	var builder strings.Builder
+
+	builder.WriteString("RoundRobin(")
+	builder.WriteString(strconv.Itoa(len(r.items)))
+	builder.WriteString(" items, next=")
+	builder.WriteString(next)
+	builder.WriteString(", rotateAmount=")
+	builder.WriteString(strconv.FormatInt(int64(r.Options.RotateAmount), 10))
+	builder.WriteString(", serves=[")
+
+	for i := range r.items {
+		if i > 0 {
+			builder.WriteByte(' ')
+		}
+
+		builder.WriteString(r.items[i].value)
+		builder.WriteByte(':')
+		builder.WriteString(strconv.FormatInt(int64(atomic.LoadInt32(&r.items[i].Statistics.ServesCount)), 10))
+	}
+
+	builder.WriteString("])")
+
+	return builder.String()
+}
+
+// Clone returns an independent copy of the round-robin, including its items, statistics, rotation position and
+// options. Serving or mutating the copy does not affect the original and vice versa.
+func (r *RoundRobin) Clone() (clone *RoundRobin) {
+	r.mutex.Lock()
+
+	defer r.mutex.Unlock()
+
+	return r.clone()
+}
+
+// clone returns an independent copy of the round-robin, sharing no mutable state with it. It must be called
+// with the mutex held.
+func (r *RoundRobin) clone() (clone *RoundRobin) {
+	clone = &RoundRobin{
+		items:                  slices.Clone(r.items),
+		nextItemIndex:          r.nextItemIndex,
+		currentItemServesCount: r.currentItemServesCount,
+		generations:            r.generations,
+		quotaServes:            slices.Clone(r.quotaServes),
+		weighted:               r.weighted,
+	}
+
+	clone.setOptions(r.copyOptions())
+
+	for _, item := range clone.items {
+		clone.itemsMap.Store(item.value, struct{}{})
+	}
+
+	for value, ring := range r.histories {
+		if clone.histories == nil {
+			clone.histories = make(map[string]*historyRing, len(r.histories))
+		}
+
+		clone.histories[value] = &historyRing{
+			entries: slices.Clone(ring.entries),
+			next:    ring.next,
+			full:    ring.full,
+		}
+	}
+
+	return
+}
+
+// CurrentOptions returns a snapshot of the round-robin options, taken with the mutex held, so that it is safe to
+// call while other goroutines update the options. Pass it as the expected options of CompareAndSetOptions.
+func (r *RoundRobin) CurrentOptions() (options Options) {
+	r.mutex.Lock()
+
+	defer r.mutex.Unlock()
+
+	return r.Options
+}
+
+// CompareAndSetOptions replaces the round-robin options with the given options only if expected, a snapshot
+// returned by CurrentOptions, still equals the current options, and reports whether they were replaced. This lets
+// multiple controllers update the configuration without losing each other's updates. Function options cannot be
+// compared, so every update made through the round-robin's methods also bumps a version recorded in the snapshot:
+// options built by hand or taken before another update are rejected, as are snapshots whose settings differ from
+// options written to the Options field directly. It returns ErrInvalidRotateAmount without replacing the options
+// if options.RotateAmount is below 1.
+func (r *RoundRobin) CompareAndSetOptions(expected, options Options) (swapped bool, err error) {
+	if options.RotateAmount < 1 {
+		err = fmt.Errorf("%w: %d", ErrInvalidRotateAmount, options.RotateAmount)
+
+		return
+	}
+
+	r.mutex.Lock()
+
+	defer r.mutex.Unlock()
+
+	if expected.version != r.Options.version || encodeOptions(expected) != encodeOptions(r.Options) {
+		return
+	}
+
+	r.setOptions(options)
+
+	swapped = true
+
+	return
+}
+
+// setOptions replaces the options of the round-robin, bumping their version. It must be called with the mutex
+// held.
+func (r *RoundRobin) setOptions(options Options) {
+	options.version = r.Options.version + 1
+
+	r.Options = options
+
+	r.trackContention.Store(options.TrackContention)
+}
+
+// RoundRobinInterface defines the interface for a round-robin mechanism, abstracting the functionality
+// to add items and retrieve the next item in sequence. This facilitates testing and alternative implementations.
+type RoundRobinInterface interface {
+	// Items method retrieves a copy of the items  in the round-robin sequence.
+	Items() (items []Item)
+	// Add method allows adding one or more items to the round-robin.
+	Add(values ...string)
+	// Next method retrieves the next item in the round-robin sequence.
+	Next() (item Item)
+}
+
+// Options holds configuration settings for the round-robin, such as rotation amount.
+// This allows customization of the round-robin behavior.
+type Options struct {
+	// RotateAmount specifies the number of serves before rotating to the next item.
+	RotateAmount int32
+	// StatsPaused, when true, stops serve counts from being incremented while items are still served.
+	StatsPaused bool
+	// ErrorOnDuplicate makes AddErr report ErrDuplicateItem for values that are already present instead of
+	// silently ignoring them.
+	ErrorOnDuplicate bool
+	// TrackContention enables measuring the time serving calls and GetOrAdd spend waiting to acquire the internal
+	// mutex, reported by ContentionStats. It takes effect when the round-robin is constructed, or when the options
+	// are replaced with CompareAndSetOptions or UnmarshalJSON.
+	TrackContention bool
+	// InitialServes is the serve count every item starts with when the round-robin is constructed, seeding a
+	// common baseline when restarting without restoring full statistics.
+	InitialServes int32
+	// CountOnComplete defers incrementing the serve count of items selected by the round-robin, as by Next,
+	// NextPreferred or NextDistinctForKey, until the completion function returned by NextWithCompletion is
+	// called. Serve records an explicit serve and always counts it immediately.
+	CountOnComplete bool
+	// SerializedMode serves the calls that serve an item or advance the rotation, such as Next, Serve or
+	// NextWithCompletion, strictly in the order they arrived, making the item each call receives deterministic
+	// under concurrency. It should be set at construction.
+	SerializedMode bool
+	// ErrorOnUnknownStats makes SetStats report ErrItemNotFound for values that are not part of the round-robin
+	// instead of ignoring them.
+	ErrorOnUnknownStats bool
+	// TrackHistory is the number of recent events, such as serves and state changes, kept per item and
+	// reported by ItemHistory. Zero disables history tracking.
+	TrackHistory int
+	// QuotaPerWindow caps the number of serves of the whole pool within any rolling QuotaWindow: once it is used
+	// up, serving fails with ErrQuotaExceeded until older serves leave the window. Serve is never refused, but
+	// counts against the quota. Zero, or a zero QuotaWindow, disables the quota.
+	QuotaPerWindow int
+	// QuotaWindow is the length of the rolling window of QuotaPerWindow, measured with Now.
+	QuotaWindow time.Duration
+	// OnServe, if set, is called with each served item. Like OnRotate, it is called after the mutex is released,
+	// so it may use the round-robin, but calls from concurrent serves may interleave.
+	OnServe func(item Item)
+	// OnRotate, if set, is called whenever the rotation advances from one item to another at the end of a turn.
+	OnRotate func(from, to Item)
+	// Strategy picks the item each serve goes to. It defaults to StrategyRoundRobin.
+	Strategy Strategy
+	// Now returns the current time, used to measure cooldowns. It defaults to time.Now and can be replaced to
+	// control time, for example in tests.
+	Now func() (now time.Time)
+	// version counts the updates of the options made by the round-robin, letting CompareAndSetOptions detect
+	// stale snapshots.
+	version uint64
+}
+
+// availablePollInterval bounds how long NextAvailable waits before checking again for an eligible item.
+const availablePollInterval = 10 * time.Millisecond
+
+var (
+	// ErrNoItems indicates that no items are available for operation, typically used when initializing
+	// a new RoundRobin instance without any items.
+	ErrNoItems = errors.New("no items")
+	// ErrItemNotFound indicates that an operation targeted a value that is not part of the round-robin.
+	ErrItemNotFound = errors.New("item not found")
+	// ErrDuplicateItem indicates that a value being added is already part of the round-robin.
+	ErrDuplicateItem = errors.New("duplicate item")
+	// ErrInvalidRotateAmount indicates that Options.RotateAmount is below 1.
+	ErrInvalidRotateAmount = errors.New("invalid rotate amount")
+	// ErrQuotaExceeded indicates that the pool has served Options.QuotaPerWindow times within the current
+	// Options.QuotaWindow.
+	ErrQuotaExceeded = errors.New("quota exceeded")
+	// ErrNotInTransaction indicates that a transaction operation targeted a round-robin that is not part of the
+	// transaction.
+	ErrNotInTransaction = errors.New("round-robin not in transaction")
+	// ErrNoDistinctItems indicates that every eligible item has already been used for a key.
+	ErrNoDistinctItems = errors.New("no distinct items")
+
+	// Interface assertions verify at compile time that the types implement the specified interfaces.
+	_ ItemInterface       = (*Item)(nil)
+	_ ItemInterface       = (*ItemHandle)(nil)
+	_ StatisticsInterface = (*Statistics)(nil)
+	_ RoundRobinInterface = (*RoundRobin)(nil)
+	_ fmt.Stringer        = (*RoundRobin)(nil)
+
+	// DefaultOptions provides a set of default configuration options for new round-robin instances,
+	// simplifying the initialization process.
+	DefaultOptions = Options{
+		RotateAmount: 1,
+	}
+)
+
+// New creates a new RoundRobin instance with default options. It initializes the round-robin with a set of initial items,
+// returning an error if no items are provided.
+func New(items ...string) (rr *RoundRobin, err error) {
+	return NewWithOptions(DefaultOptions, items...)
+}
+
+// NewWithOptions creates a new RoundRobin instance with custom options. It allows for greater flexibility
+// in configuring the round-robin behavior and initializes the instance with a set of initial items. It returns
+// ErrInvalidRotateAmount if options.RotateAmount is below 1.
+func NewWithOptions(options Options, items ...string) (rr *RoundRobin, err error) {
+	if len(items) == 0 {
+		err = ErrNoItems
+
+		return
+	}
+
+	if options.RotateAmount < 1 {
+		err = fmt.Errorf("%w: %d", ErrInvalidRotateAmount, options.RotateAmount)
+
+		return
+	}
+
+	rr = &RoundRobin{}
+
+	rr.setOptions(options)
+
+	rr.Add(items...)
+
+	for i := range rr.items {
+		rr.items[i].Statistics.ServesCount = options.InitialServes
+	}
+
+	// Ensure the next item index starts from the first item.
+	rr.nextItemIndex = 1
+
+	return
+}
+
+// Option configures a round-robin created by NewWithOpts.
+type Option func(options *Options)
+
+// WithRotateAmount sets the number of consecutive serves each item gets before the rotation advances.
+func WithRotateAmount(rotateAmount int32) (option Option) {
+	return func(options *Options) {
+		options.RotateAmount = rotateAmount
+	}
+}
+
+// NewWithOpts creates a new RoundRobin instance with DefaultOptions adjusted by the given options, like
+// NewWithOptions.
+func NewWithOpts(items []string, opts ...Option) (rr *RoundRobin, err error) {
+	options := DefaultOptions
+
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return NewWithOptions(options, items...)
+}
@@ -1,9 +1,18 @@
 package roundrobin_test
 
 import (
+	"context"
 	"errors"
+	"maps"
+	"reflect"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	hqgoroundrobin "github.com/hueristiq/hq-go-roundrobin"
 )
@@ -28,6 +37,50 @@ func TestNewWithOptions(t *testing.T) {
 	if err != nil {
 		t.Errorf("Failed to create a new RoundRobin instance with options: %s", err)
 	}
+
+	for _, rotateAmount := range []int32{0, -5} {
+		options.RotateAmount = rotateAmount
+
+		if _, err := hqgoroundrobin.NewWithOptions(options, "item1"); !errors.Is(err, hqgoroundrobin.ErrInvalidRotateAmount) {
+			t.Errorf("Expected ErrInvalidRotateAmount error for RotateAmount %d, got %v", rotateAmount, err)
+		}
+	}
+
+	options.RotateAmount = 1
+
+	if _, err := hqgoroundrobin.NewWithOptions(options, "item1"); err != nil {
+		t.Errorf("Failed to create a new RoundRobin instance with RotateAmount 1: %s", err)
+	}
+}
+
+func TestNewWithOpts(t *testing.T) {
+	t.Parallel()
+
+	items := []string{"item1", "item2", "item3"}
+
+	withOpts, err := hqgoroundrobin.NewWithOpts(items, hqgoroundrobin.WithRotateAmount(2))
+	if err != nil {
+		t.Fatalf("Failed to create a new RoundRobin instance with opts: %s", err)
+	}
+
+	withOptions, _ := hqgoroundrobin.NewWithOptions(hqgoroundrobin.Options{RotateAmount: 2}, items...)
+
+	for range 8 {
+		if got, want := withOpts.Next().Value(), withOptions.Next().Value(); got != want {
+			t.Errorf("Unexpected item: got %s, want %s", got, want)
+		}
+	}
+
+	defaults, _ := hqgoroundrobin.NewWithOpts(items)
+	withDefaultOptions, _ := hqgoroundrobin.NewWithOptions(hqgoroundrobin.DefaultOptions, items...)
+
+	if !reflect.DeepEqual(defaults.Options, withDefaultOptions.Options) {
+		t.Errorf("Unexpected options: got %+v, want %+v", defaults.Options, withDefaultOptions.Options)
+	}
+
+	if _, err := hqgoroundrobin.NewWithOpts(items, hqgoroundrobin.WithRotateAmount(0)); !errors.Is(err, hqgoroundrobin.ErrInvalidRotateAmount) {
+		t.Errorf("Expected ErrInvalidRotateAmount error, got %v", err)
+	}
 }
 
 func TestAddAndNext(t *testing.T) {
@@ -106,3 +159,1697 @@ func TestNoItemsError(t *testing.T) {
 		t.Errorf("Expected ErrNoItems error, got %v", err)
 	}
 }
+
+func TestCompareAndSetOptions(t *testing.T) {
+	t.Parallel()
+
+	rr, _ := hqgoroundrobin.New("item1", "item2")
+
+	stale := rr.CurrentOptions()
+
+	if swapped, err := rr.CompareAndSetOptions(stale, hqgoroundrobin.Options{RotateAmount: 2}); !swapped || err != nil {
+		t.Fatalf("Expected the first update to be applied, got %t (%v)", swapped, err)
+	}
+
+	if swapped, _ := rr.CompareAndSetOptions(stale, hqgoroundrobin.Options{RotateAmount: 3}); swapped {
+		t.Error("Expected the stale update to be rejected")
+	}
+
+	if got := rr.CurrentOptions().RotateAmount; got != 2 {
+		t.Errorf("Options were not preserved after the rejected update: got %d, want %d", got, 2)
+	}
+
+	// Options that differ only in the clock their Now closure captures must still be told apart.
+	clock := func(now time.Time) func() time.Time {
+		return func() time.Time {
+			return now
+		}
+	}
+
+	if swapped, _ := rr.CompareAndSetOptions(rr.CurrentOptions(), hqgoroundrobin.Options{RotateAmount: 2, Now: clock(time.Unix(1, 0))}); !swapped {
+		t.Fatal("Expected the update with a clock to be applied")
+	}
+
+	withClock := rr.CurrentOptions()
+
+	rr.PauseStats()
+
+	if swapped, _ := rr.CompareAndSetOptions(withClock, hqgoroundrobin.Options{RotateAmount: 2, Now: clock(time.Unix(2, 0))}); swapped {
+		t.Error("Expected the update based on options changed by PauseStats to be rejected")
+	}
+
+	if swapped, _ := rr.CompareAndSetOptions(hqgoroundrobin.Options{RotateAmount: 2, Now: clock(time.Unix(1, 0))}, hqgoroundrobin.DefaultOptions); swapped {
+		t.Error("Expected an update based on options that were never current to be rejected")
+	}
+
+	// Options written to the field directly do not bump the version, but still make older snapshots stale.
+	current := rr.CurrentOptions()
+
+	rr.Options.RotateAmount = 5
+
+	if swapped, _ := rr.CompareAndSetOptions(current, hqgoroundrobin.DefaultOptions); swapped {
+		t.Error("Expected the update based on options written directly to be rejected")
+	}
+
+	swapped, err := rr.CompareAndSetOptions(rr.CurrentOptions(), hqgoroundrobin.Options{RotateAmount: 0})
+	if swapped || !errors.Is(err, hqgoroundrobin.ErrInvalidRotateAmount) {
+		t.Errorf("Expected invalid options to be rejected with ErrInvalidRotateAmount, got %t (%v)", swapped, err)
+	}
+
+	if got := rr.CurrentOptions().RotateAmount; got != 5 {
+		t.Errorf("Options were changed by the invalid update: got %d, want %d", got, 5)
+	}
+}
+
+func TestCompareAndSetOptionsRace(t *testing.T) {
+	t.Parallel()
+
+	rr, _ := hqgoroundrobin.New("item1", "item2")
+
+	snapshot := rr.CurrentOptions()
+
+	var wins atomic.Int32
+
+	start := make(chan struct{})
+
+	wg := &sync.WaitGroup{}
+
+	for i := range 20 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			<-start
+
+			options := rr.CurrentOptions()
+
+			options.RotateAmount = int32(i + 2)
+
+			if swapped, _ := rr.CompareAndSetOptions(snapshot, options); swapped {
+				wins.Add(1)
+			}
+
+			rr.Next()
+		}()
+	}
+
+	close(start)
+
+	wg.Wait()
+
+	if got := wins.Load(); got != 1 {
+		t.Errorf("Expected exactly one of the racing updates to be applied, got %d", got)
+	}
+
+	if got := rr.CurrentOptions().RotateAmount; got < 2 {
+		t.Errorf("Expected the winning update to be applied, got rotate amount %d", got)
+	}
+}
+
+func TestServe(t *testing.T) {
+	t.Parallel()
+
+	rr, _ := hqgoroundrobin.New("item1", "item2", "item3")
+
+	rr.Next()
+
+	item, err := rr.Serve("item3")
+	if err != nil {
+		t.Fatalf("Failed to serve a named item: %s", err)
+	}
+
+	if item.Value() != "item3" || item.Statistics.ServesCount != 1 {
+		t.Errorf("Served item was incorrect: got %s (%d serves), want %s (%d serves)", item.Value(), item.Statistics.ServesCount, "item3", 1)
+	}
+
+	if next := rr.Next(); next.Value() != "item2" {
+		t.Errorf("Rotation position was changed by Serve: got %s, want %s", next.Value(), "item2")
+	}
+
+	if _, err := rr.Serve("item4"); !errors.Is(err, hqgoroundrobin.ErrItemNotFound) {
+		t.Errorf("Expected ErrItemNotFound error, got %v", err)
+	}
+}
+
+func TestMostAndLeastServed(t *testing.T) {
+	t.Parallel()
+
+	rr, _ := hqgoroundrobin.New("item1", "item2", "item3")
+
+	rr.Next()
+
+	for range 3 {
+		_, _ = rr.Serve("item2")
+	}
+
+	most, ok := rr.MostServed()
+	if !ok || most.Value() != "item2" {
+		t.Errorf("Most served item was incorrect: got %s, want %s", most.Value(), "item2")
+	}
+
+	least, ok := rr.LeastServed()
+	if !ok || least.Value() != "item3" {
+		t.Errorf("Least served item was incorrect: got %s, want %s", least.Value(), "item3")
+	}
+
+	if _, ok := (&hqgoroundrobin.RoundRobin{}).MostServed(); ok {
+		t.Error("Expected no most served item on an empty round-robin")
+	}
+}
+
+func TestValuesAndSetValues(t *testing.T) {
+	t.Parallel()
+
+	rr, _ := hqgoroundrobin.New("item1", "item2", "item3")
+
+	rr.Next()
+
+	values := rr.Values()
+
+	if err := rr.SetValues([]string{"item3", "item1", "item4"}); err != nil {
+		t.Fatalf("Failed to set values: %s", err)
+	}
+
+	if got := rr.Values(); !slices.Equal(got, []string{"item3", "item1", "item4"}) {
+		t.Errorf("Values order was not preserved: got %v", got)
+	}
+
+	if err := rr.SetValues(values); err != nil {
+		t.Fatalf("Failed to restore values: %s", err)
+	}
+
+	if got := rr.Values(); !slices.Equal(got, values) {
+		t.Errorf("Values did not round-trip: got %v, want %v", got, values)
+	}
+
+	if item, _ := rr.Serve("item1"); item.Statistics.ServesCount != 2 {
+		t.Errorf("Statistics of a retained item were not preserved: got %d, want %d", item.Statistics.ServesCount, 2)
+	}
+
+	if err := rr.SetValues(nil); !errors.Is(err, hqgoroundrobin.ErrNoItems) {
+		t.Errorf("Expected ErrNoItems error, got %v", err)
+	}
+}
+
+func TestPauseStats(t *testing.T) {
+	t.Parallel()
+
+	rr, _ := hqgoroundrobin.New("item1", "item2")
+
+	rr.PauseStats()
+
+	if item := rr.Next(); item.Value() != "item1" || item.Statistics.ServesCount != 0 {
+		t.Errorf("Paused serve was incorrect: got %s (%d serves), want %s (%d serves)", item.Value(), item.Statistics.ServesCount, "item1", 0)
+	}
+
+	rr.ResumeStats()
+
+	if item := rr.Next(); item.Value() != "item2" || item.Statistics.ServesCount != 1 {
+		t.Errorf("Resumed serve was incorrect: got %s (%d serves), want %s (%d serves)", item.Value(), item.Statistics.ServesCount, "item2", 1)
+	}
+}
+
+func TestAddErrDuplicate(t *testing.T) {
+	t.Parallel()
+
+	rr, _ := hqgoroundrobin.NewWithOptions(hqgoroundrobin.Options{RotateAmount: 1, ErrorOnDuplicate: true}, "item1", "item2")
+
+	if err := rr.AddErr("item3", "item1"); !errors.Is(err, hqgoroundrobin.ErrDuplicateItem) {
+		t.Errorf("Expected ErrDuplicateItem error, got %v", err)
+	}
+
+	if got := len(rr.Items()); got != 2 {
+		t.Errorf("Values were added despite the duplicate: got %d items, want %d", got, 2)
+	}
+
+	lenient, _ := hqgoroundrobin.New("item1")
+
+	if err := lenient.AddErr("item1", "item2"); err != nil {
+		t.Errorf("Expected duplicates to be ignored by default, got %v", err)
+	}
+}
+
+func TestStatsIterator(t *testing.T) {
+	t.Parallel()
+
+	rr, _ := hqgoroundrobin.New("item1", "item2", "item3")
+
+	for range 7 {
+		rr.Next()
+	}
+
+	var sum int64
+
+	for _, serves := range rr.Stats() {
+		sum += int64(serves)
+	}
+
+	if total := rr.TotalServes(); sum != total || total != 7 {
+		t.Errorf("Iterated serves did not match the total: got %d, want %d", sum, total)
+	}
+
+	for value := range rr.Stats() {
+		if value == "item1" {
+			break
+		}
+	}
+
+	// Breaking out of the iteration must release the mutex.
+	rr.Next()
+}
+
+func TestContentionStats(t *testing.T) {
+	t.Parallel()
+
+	rr, _ := hqgoroundrobin.NewWithOptions(hqgoroundrobin.Options{RotateAmount: 1, TrackContention: true}, "item1", "item2")
+
+	done := make(chan struct{})
+
+	// The iterator holds the mutex, so a concurrent Next has to wait for it.
+	rr.Stats()(func(_ string, _ int32) bool {
+		go func() {
+			defer close(done)
+
+			rr.Next()
+		}()
+
+		time.Sleep(10 * time.Millisecond)
+
+		return false
+	})
+
+	<-done
+
+	waits, totalWait := rr.ContentionStats()
+	if waits == 0 || totalWait <= 0 {
+		t.Errorf("Contention was not recorded: got %d waits totalling %s", waits, totalWait)
+	}
+
+	untracked, _ := hqgoroundrobin.New("item1")
+
+	done = make(chan struct{})
+
+	untracked.Stats()(func(_ string, _ int32) bool {
+		go func() {
+			defer close(done)
+
+			untracked.Next()
+		}()
+
+		time.Sleep(10 * time.Millisecond)
+
+		return false
+	})
+
+	<-done
+
+	if waits, _ := untracked.ContentionStats(); waits != 0 {
+		t.Errorf("Contention was recorded without tracking: got %d waits", waits)
+	}
+}
+
+func TestTrace(t *testing.T) {
+	t.Parallel()
+
+	rr, _ := hqgoroundrobin.NewWithOptions(hqgoroundrobin.Options{RotateAmount: 2}, "item1", "item2", "item3")
+
+	rr.Next()
+
+	trace := rr.Trace(7)
+
+	if total := rr.TotalServes(); total != 1 {
+		t.Errorf("Trace modified the statistics: got %d total serves, want %d", total, 1)
+	}
+
+	for i, value := range trace {
+		if item := rr.Next(); item.Value() != value {
+			t.Errorf("Trace diverged from Next at position %d: got %s, want %s", i, value, item.Value())
+		}
+	}
+}
+
+func TestSubPool(t *testing.T) {
+	t.Parallel()
+
+	rr, _ := hqgoroundrobin.New("item1", "item2", "item3")
+
+	rr.Next()
+
+	sub, err := rr.SubPool(func(item hqgoroundrobin.Item) bool {
+		return item.Value() != "item2"
+	})
+	if err != nil {
+		t.Fatalf("Failed to create a sub-pool: %s", err)
+	}
+
+	if got := sub.Values(); !slices.Equal(got, []string{"item1", "item3"}) {
+		t.Errorf("Sub-pool items were incorrect: got %v", got)
+	}
+
+	if item := sub.Next(); item.Value() != "item1" || item.Statistics.ServesCount != 2 {
+		t.Errorf("Sub-pool did not carry over statistics: got %s (%d serves), want %s (%d serves)", item.Value(), item.Statistics.ServesCount, "item1", 2)
+	}
+
+	if item := rr.Next(); item.Value() != "item2" {
+		t.Errorf("Sub-pool rotation affected the parent: got %s, want %s", item.Value(), "item2")
+	}
+
+	if _, err := rr.SubPool(func(hqgoroundrobin.Item) bool { return false }); !errors.Is(err, hqgoroundrobin.ErrNoItems) {
+		t.Errorf("Expected ErrNoItems error, got %v", err)
+	}
+}
+
+func TestInitialServes(t *testing.T) {
+	t.Parallel()
+
+	rr, _ := hqgoroundrobin.NewWithOptions(hqgoroundrobin.Options{RotateAmount: 1, InitialServes: 10}, "item1", "item2", "item3")
+
+	for value, serves := range rr.Stats() {
+		if serves != 10 {
+			t.Errorf("Item %s did not start at the baseline: got %d, want %d", value, serves, 10)
+		}
+	}
+}
+
+func TestItemHandle(t *testing.T) {
+	t.Parallel()
+
+	rr, _ := hqgoroundrobin.New("item1", "item2")
+
+	handle := rr.NextHandle()
+
+	_, _ = rr.Serve("item1")
+
+	if serves, err := handle.Serves(); err != nil || serves != 2 {
+		t.Errorf("Handle did not resolve the current serve count: got %d (%v), want %d", serves, err, 2)
+	}
+
+	if !handle.Valid() {
+		t.Error("Expected the handle to be valid while the item is in the round-robin")
+	}
+
+	_ = rr.SetValues([]string{"item2"})
+
+	if handle.Valid() {
+		t.Error("Expected the handle to be invalid after the item was removed")
+	}
+
+	if _, err := handle.Serves(); !errors.Is(err, hqgoroundrobin.ErrItemNotFound) {
+		t.Errorf("Expected ErrItemNotFound error, got %v", err)
+	}
+
+	// An item added again with the same value is a different item, which the stale handle must not resolve to.
+	rr.Add("item1")
+
+	if handle.Valid() {
+		t.Error("Expected the handle to stay invalid after an item with the same value was added again")
+	}
+
+	if err := handle.ReportFailure(time.Hour); !errors.Is(err, hqgoroundrobin.ErrItemNotFound) {
+		t.Errorf("Expected ErrItemNotFound error, got %v", err)
+	}
+
+	if !rr.AllEligible() {
+		t.Error("Failure reported through a stale handle benched the item that replaced it")
+	}
+
+	current := rr.NextHandle()
+
+	if err := current.ReportFailure(time.Hour); err != nil {
+		t.Fatalf("Failed to report a failure: %s", err)
+	}
+
+	if got, want := rr.IneligibleValues(), []string{current.Value()}; !slices.Equal(got, want) {
+		t.Errorf("Expected the failed item to be benched: got %v, want %v", got, want)
+	}
+
+	var zero hqgoroundrobin.ItemHandle
+
+	if zero.Valid() {
+		t.Error("Expected the zero handle to be invalid")
+	}
+
+	if _, err := zero.Serves(); !errors.Is(err, hqgoroundrobin.ErrItemNotFound) {
+		t.Errorf("Expected ErrItemNotFound error from the zero handle, got %v", err)
+	}
+}
+
+func TestSeededShuffle(t *testing.T) {
+	t.Parallel()
+
+	a, _ := hqgoroundrobin.New("item1", "item2", "item3", "item4", "item5")
+	b, _ := hqgoroundrobin.New("item5", "item4", "item3", "item2", "item1")
+
+	a.SeededShuffle(42)
+	b.SeededShuffle(42)
+
+	if !slices.Equal(a.Values(), b.Values()) {
+		t.Errorf("Identically seeded shuffles diverged: got %v and %v", a.Values(), b.Values())
+	}
+
+	if item := a.Next(); item.Value() != a.Values()[0] {
+		t.Errorf("Rotation did not restart from the first item: got %s, want %s", item.Value(), a.Values()[0])
+	}
+}
+
+func TestCountOnComplete(t *testing.T) {
+	t.Parallel()
+
+	rr, _ := hqgoroundrobin.NewWithOptions(hqgoroundrobin.Options{RotateAmount: 1, CountOnComplete: true}, "item1", "item2")
+
+	completions := make([]func(), 0, 4)
+
+	for range 4 {
+		_, complete := rr.NextWithCompletion()
+
+		completions = append(completions, complete)
+	}
+
+	// Complete only the serves of item1, one of them twice.
+	completions[0]()
+	completions[0]()
+	completions[2]()
+
+	// Serves selected without a completion function are never counted.
+	rr.NextPreferred([]string{"item2"})
+
+	_, _ = rr.NextDistinctForKey("key", nil)
+
+	for value, serves := range rr.Stats() {
+		want := int32(0)
+
+		if value == "item1" {
+			want = 2
+		}
+
+		if serves != want {
+			t.Errorf("Serve count of %s did not match completions: got %d, want %d", value, serves, want)
+		}
+	}
+}
+
+func TestCountOnCompleteReplacedItem(t *testing.T) {
+	t.Parallel()
+
+	rr, _ := hqgoroundrobin.NewWithOptions(hqgoroundrobin.Options{RotateAmount: 1, CountOnComplete: true}, "item1", "item2")
+
+	item, complete := rr.NextWithCompletion()
+
+	_ = rr.SetValues([]string{"item2"})
+
+	rr.Add(item.Value())
+
+	complete()
+
+	if serves := rr.StatsMap()[item.Value()]; serves != 0 {
+		t.Errorf("Abandoned serve was counted against the item that replaced %s: got %d, want %d", item.Value(), serves, 0)
+	}
+}
+
+func BenchmarkNext(b *testing.B) {
+	rr, _ := hqgoroundrobin.New("item1", "item2", "item3")
+
+	b.ReportAllocs()
+
+	for b.Loop() {
+		rr.Next()
+	}
+}
+
+func BenchmarkNextValue(b *testing.B) {
+	rr, _ := hqgoroundrobin.New("item1", "item2", "item3")
+
+	b.ReportAllocs()
+
+	for b.Loop() {
+		rr.NextValue()
+	}
+}
+
+func TestSort(t *testing.T) {
+	t.Parallel()
+
+	rr, _ := hqgoroundrobin.NewWithOptions(hqgoroundrobin.Options{RotateAmount: 2}, "item3", "item1", "item2")
+
+	rr.Next()
+
+	rr.Sort(func(a, b hqgoroundrobin.Item) bool {
+		return a.Value() < b.Value()
+	})
+
+	if got := rr.Values(); !slices.Equal(got, []string{"item1", "item2", "item3"}) {
+		t.Errorf("Items were not sorted: got %v", got)
+	}
+
+	// item3 was being served and has one serve left in its turn, then rotation continues from its new position.
+	for _, want := range []string{"item3", "item1", "item1"} {
+		if item := rr.Next(); item.Value() != want {
+			t.Errorf("Serving order after sorting was incorrect: got %s, want %s", item.Value(), want)
+		}
+	}
+}
+
+func TestEffectiveOrder(t *testing.T) {
+	t.Parallel()
+
+	for _, rotateAmount := range []int32{1, 2} {
+		rr, _ := hqgoroundrobin.NewWithOptions(hqgoroundrobin.Options{RotateAmount: rotateAmount}, "item1", "item2", "item3")
+
+		rr.Next()
+
+		order := rr.EffectiveOrder()
+
+		if len(order) != 3*int(rotateAmount) {
+			t.Errorf("Effective order did not span one cycle: got %d serves, want %d", len(order), 3*rotateAmount)
+		}
+
+		for i, value := range order {
+			if item := rr.Next(); item.Value() != value {
+				t.Errorf("Effective order diverged from Next at position %d: got %s, want %s", i, value, item.Value())
+			}
+		}
+	}
+
+	for _, rotateAmount := range []int32{1, 2} {
+		rr, _ := hqgoroundrobin.NewWithOptions(hqgoroundrobin.Options{RotateAmount: rotateAmount}, "item2", "item3")
+
+		rr.AddWeighted("item1", 5)
+
+		rr.Next()
+
+		order := rr.EffectiveOrder()
+
+		if len(order) != 7*int(rotateAmount) {
+			t.Errorf("Weighted effective order did not span one cycle: got %d serves, want %d", len(order), 7*rotateAmount)
+		}
+
+		counts := make(map[string]int32)
+
+		for i, value := range order {
+			counts[value]++
+
+			if item := rr.Next(); item.Value() != value {
+				t.Errorf("Weighted effective order diverged from Next at position %d: got %s, want %s", i, value, item.Value())
+			}
+		}
+
+		want := map[string]int32{"item1": 5 * rotateAmount, "item2": rotateAmount, "item3": rotateAmount}
+
+		if !maps.Equal(counts, want) {
+			t.Errorf("Weighted effective order did not serve items in proportion to their weights: got %v, want %v", counts, want)
+		}
+	}
+}
+
+func TestFullReset(t *testing.T) {
+	t.Parallel()
+
+	rr, _ := hqgoroundrobin.New("item1", "item2", "item3")
+
+	wg := &sync.WaitGroup{}
+
+	for range 10 {
+		wg.Add(2)
+
+		go func() {
+			defer wg.Done()
+
+			for range 100 {
+				rr.Next()
+			}
+		}()
+
+		go func() {
+			defer wg.Done()
+
+			rr.FullReset()
+		}()
+	}
+
+	wg.Wait()
+
+	rr.FullReset()
+
+	if total := rr.TotalServes(); total != 0 {
+		t.Errorf("Statistics were not reset: got %d total serves, want %d", total, 0)
+	}
+
+	if item := rr.Next(); item.Value() != "item1" {
+		t.Errorf("Rotation was not reset: got %s, want %s", item.Value(), "item1")
+	}
+}
+
+func TestNextPreferred(t *testing.T) {
+	t.Parallel()
+
+	rr, _ := hqgoroundrobin.New("item1", "item2", "item3")
+
+	if item := rr.NextPreferred([]string{"item4", "item3"}); item.Value() != "item3" || item.Statistics.ServesCount != 1 {
+		t.Errorf("Preferred item was not served: got %s (%d serves), want %s (%d serves)", item.Value(), item.Statistics.ServesCount, "item3", 1)
+	}
+
+	if item := rr.NextPreferred([]string{"item4"}); item.Value() != "item1" {
+		t.Errorf("Expected a fallback to the rotation: got %s, want %s", item.Value(), "item1")
+	}
+
+	if item := rr.Next(); item.Value() != "item2" {
+		t.Errorf("Rotation was changed by the preferred serve: got %s, want %s", item.Value(), "item2")
+	}
+}
+
+func TestNextInfo(t *testing.T) {
+	t.Parallel()
+
+	rotations := 0
+
+	options := hqgoroundrobin.Options{
+		RotateAmount: 3,
+		OnRotate: func(_, _ hqgoroundrobin.Item) {
+			rotations++
+		},
+	}
+
+	rr, _ := hqgoroundrobin.NewWithOptions(options, "item1", "item2")
+
+	for i := range 12 {
+		// The first serve starts the rotation rather than advancing it.
+		want := i > 0 && i%3 == 0
+
+		if _, rotated := rr.NextInfo(); rotated != want {
+			t.Errorf("Unexpected rotation on call %d: got %t, want %t", i+1, rotated, want)
+		}
+	}
+
+	if rotations != 3 {
+		t.Errorf("Unexpected OnRotate calls: got %d, want %d", rotations, 3)
+	}
+
+	single, _ := hqgoroundrobin.New("item1")
+
+	for i := range 3 {
+		if _, rotated := single.NextInfo(); rotated {
+			t.Errorf("Unexpected rotation of a single item on call %d", i+1)
+		}
+	}
+
+	weighted := &hqgoroundrobin.RoundRobin{Options: hqgoroundrobin.DefaultOptions}
+
+	weighted.AddWeighted("a", 5)
+	weighted.AddWeighted("b", 1)
+	weighted.AddWeighted("c", 1)
+
+	want := []bool{false, false, true, true, true, true, false}
+
+	for i, wantRotated := range want {
+		if item, rotated := weighted.NextInfo(); rotated != wantRotated {
+			t.Errorf("Unexpected weighted rotation on call %d serving %s: got %t, want %t", i+1, item.Value(), rotated, wantRotated)
+		}
+	}
+}
+
+func TestRotateAmountSequence(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		rotateAmount int32
+		want         []string
+	}{
+		{1, []string{"a", "b", "c", "a", "b", "c"}},
+		{2, []string{"a", "a", "b", "b", "c", "c", "a", "a", "b", "b", "c", "c"}},
+		{3, []string{"a", "a", "a", "b", "b", "b", "c", "c", "c", "a", "a", "a", "b", "b", "b", "c", "c", "c"}},
+	}
+
+	for _, tt := range tests {
+		rr, _ := hqgoroundrobin.NewWithOptions(hqgoroundrobin.Options{RotateAmount: tt.rotateAmount}, "a", "b", "c")
+
+		got := make([]string, 0, len(tt.want))
+
+		for range tt.want {
+			got = append(got, rr.Next().Value())
+		}
+
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("Sequence for RotateAmount %d was incorrect: got %v, want %v", tt.rotateAmount, got, tt.want)
+		}
+	}
+}
+
+func TestGetOrAddConcurrent(t *testing.T) {
+	t.Parallel()
+
+	rr, _ := hqgoroundrobin.New("item1")
+
+	wg := &sync.WaitGroup{}
+
+	for i := range 100 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			rr.GetOrAdd("item" + strconv.Itoa(i%5+1))
+		}()
+	}
+
+	wg.Wait()
+
+	values := rr.Values()
+
+	slices.Sort(values)
+
+	if !slices.Equal(values, []string{"item1", "item2", "item3", "item4", "item5"}) {
+		t.Errorf("Pool was inconsistent after concurrent GetOrAdd: got %v", values)
+	}
+
+	if total := rr.TotalServes(); total != 100 {
+		t.Errorf("Total serves were incorrect: got %d, want %d", total, 100)
+	}
+}
+
+func TestPeek(t *testing.T) {
+	t.Parallel()
+
+	rr, _ := hqgoroundrobin.NewWithOptions(hqgoroundrobin.Options{RotateAmount: 2}, "item1", "item2")
+
+	for range 5 {
+		first := rr.Peek()
+		second := rr.Peek()
+
+		if first.Value() != second.Value() {
+			t.Errorf("Consecutive peeks disagreed: got %s and %s", first.Value(), second.Value())
+		}
+
+		total := rr.TotalServes()
+
+		if item := rr.Next(); item.Value() != first.Value() {
+			t.Errorf("Next did not serve the peeked item: got %s, want %s", item.Value(), first.Value())
+		}
+
+		if served := rr.TotalServes(); served != total+1 {
+			t.Errorf("Peeking changed the statistics: got %d total serves, want %d", served, total+1)
+		}
+	}
+
+	if item := rr.Peek(); item.Statistics.ServesCount != 3 {
+		t.Errorf("Peeked item statistics were incorrect: got %d, want %d", item.Statistics.ServesCount, 3)
+	}
+}
+
+func TestNextENoItems(t *testing.T) {
+	t.Parallel()
+
+	rr := &hqgoroundrobin.RoundRobin{}
+
+	if _, err := rr.NextE(); !errors.Is(err, hqgoroundrobin.ErrNoItems) {
+		t.Errorf("Expected ErrNoItems error, got %v", err)
+	}
+
+	if item := rr.Next(); item.Value() != "" {
+		t.Errorf("Expected a zero item, got %s", item.Value())
+	}
+
+	rr.Add("item1")
+
+	if item, err := rr.NextE(); err != nil || item.Value() != "item1" {
+		t.Errorf("Expected item1 once an item was added, got %s (%v)", item.Value(), err)
+	}
+}
+
+func TestSerializedMode(t *testing.T) {
+	t.Parallel()
+
+	options := hqgoroundrobin.Options{RotateAmount: 2, SerializedMode: true}
+
+	rr, _ := hqgoroundrobin.NewWithOptions(options, "item1", "item2", "item3", "item4")
+
+	want := rr.Trace(100)
+
+	got := make([]string, 100)
+
+	wg := &sync.WaitGroup{}
+
+	for range 100 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			ticket, item := rr.NextTicket()
+
+			got[ticket] = item.Value()
+		}()
+	}
+
+	wg.Wait()
+
+	if !slices.Equal(got, want) {
+		t.Errorf("Serialized serves did not follow ticket order: got %v, want %v", got, want)
+	}
+}
+
+func TestSerializedModeMixed(t *testing.T) {
+	t.Parallel()
+
+	options := hqgoroundrobin.Options{RotateAmount: 2, SerializedMode: true}
+
+	rr, _ := hqgoroundrobin.NewWithOptions(options, "item1", "item2", "item3", "item4")
+
+	want := rr.Trace(100)
+
+	got := make([]string, 100)
+
+	wg := &sync.WaitGroup{}
+
+	for i := range 100 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			runtime.Gosched()
+
+			// Serves without a ticket of their own still take a turn, so they cannot shift the serves of tickets.
+			if i%2 == 1 {
+				_, complete := rr.NextWithCompletion()
+
+				complete()
+
+				return
+			}
+
+			ticket, item := rr.NextTicket()
+
+			got[ticket] = item.Value()
+		}()
+	}
+
+	wg.Wait()
+
+	for ticket, value := range got {
+		if value != "" && value != want[ticket] {
+			t.Errorf("Serialized serve of ticket %d was shifted by a concurrent serve: got %s, want %s", ticket, value, want[ticket])
+		}
+	}
+}
+
+func TestAddWeighted(t *testing.T) {
+	t.Parallel()
+
+	rr, _ := hqgoroundrobin.New("item2", "item3")
+
+	rr.AddWeighted("item1", 5)
+
+	counts := make(map[string]int)
+
+	for range 700 {
+		counts[rr.Next().Value()]++
+	}
+
+	want := map[string]int{"item1": 500, "item2": 100, "item3": 100}
+
+	for value, count := range want {
+		if counts[value] != count {
+			t.Errorf("Weighted distribution of %s was incorrect: got %d, want %d", value, counts[value], count)
+		}
+	}
+
+	// Smooth weighted round-robin spreads the serves of the heavy item out instead of clumping them.
+	if order := rr.EffectiveOrder(); slices.Equal(order[:5], []string{"item1", "item1", "item1", "item1", "item1"}) {
+		t.Errorf("Weighted serves were clumped together: got %v", order)
+	}
+}
+
+func TestReset(t *testing.T) {
+	t.Parallel()
+
+	rr, _ := hqgoroundrobin.NewWithOptions(hqgoroundrobin.Options{RotateAmount: 2}, "item1", "item2", "item3")
+
+	for range 5 {
+		rr.Next()
+	}
+
+	rr.Reset()
+
+	for value, serves := range rr.Stats() {
+		if serves != 0 {
+			t.Errorf("Statistics of %s were not reset: got %d, want %d", value, serves, 0)
+		}
+	}
+
+	if item := rr.Next(); item.Value() != "item1" {
+		t.Errorf("Rotation did not restart from the first item: got %s, want %s", item.Value(), "item1")
+	}
+
+	if got := len(rr.Values()); got != 3 {
+		t.Errorf("Reset removed items: got %d items, want %d", got, 3)
+	}
+}
+
+func TestLenAndContains(t *testing.T) {
+	t.Parallel()
+
+	rr := &hqgoroundrobin.RoundRobin{}
+
+	if rr.Len() != 0 || rr.Contains("item1") {
+		t.Errorf("Empty round-robin was not empty: got %d items", rr.Len())
+	}
+
+	rr.Add("item1")
+
+	if rr.Len() != 1 || !rr.Contains("item1") || rr.Contains("item2") {
+		t.Errorf("Single item round-robin was incorrect: got %d items", rr.Len())
+	}
+
+	rr.Add("item2", "item3")
+
+	_ = rr.SetValues([]string{"item1", "item3"})
+
+	if rr.Len() != 2 || rr.Contains("item2") || !rr.Contains("item3") {
+		t.Errorf("Round-robin was incorrect after removal: got %d items", rr.Len())
+	}
+}
+
+func TestStatsMap(t *testing.T) {
+	t.Parallel()
+
+	rr, _ := hqgoroundrobin.New("item1", "item2", "item3")
+
+	for range 4 {
+		rr.Next()
+	}
+
+	stats := rr.StatsMap()
+
+	want := map[string]int32{"item1": 2, "item2": 1, "item3": 1}
+
+	if !maps.Equal(stats, want) {
+		t.Errorf("Stats snapshot was incorrect: got %v, want %v", stats, want)
+	}
+
+	stats["item1"] = 100
+
+	if got := rr.StatsMap()["item1"]; got != 2 {
+		t.Errorf("Modifying the snapshot affected the round-robin: got %d, want %d", got, 2)
+	}
+}
+
+func TestSetStats(t *testing.T) {
+	t.Parallel()
+
+	rr, _ := hqgoroundrobin.NewWithOptions(hqgoroundrobin.Options{RotateAmount: 1, ErrorOnUnknownStats: true}, "item1", "item2", "item3")
+
+	rr.Next()
+
+	if err := rr.SetStats(map[string]int32{"item1": 5, "item2": 3}); err != nil {
+		t.Fatalf("Failed to set stats: %s", err)
+	}
+
+	if want := map[string]int32{"item1": 5, "item2": 3, "item3": 0}; !maps.Equal(rr.StatsMap(), want) {
+		t.Errorf("Stats were not replaced: got %v, want %v", rr.StatsMap(), want)
+	}
+
+	if least, _ := rr.LeastServed(); least.Value() != "item3" {
+		t.Errorf("Least served item did not honor the imposed stats: got %s, want %s", least.Value(), "item3")
+	}
+
+	if err := rr.SetStats(map[string]int32{"item4": 1}); !errors.Is(err, hqgoroundrobin.ErrItemNotFound) {
+		t.Errorf("Expected ErrItemNotFound error, got %v", err)
+	}
+}
+
+func TestNextN(t *testing.T) {
+	t.Parallel()
+
+	options := hqgoroundrobin.Options{RotateAmount: 2}
+
+	batched, _ := hqgoroundrobin.NewWithOptions(options, "item1", "item2", "item3")
+	sequential, _ := hqgoroundrobin.NewWithOptions(options, "item1", "item2", "item3")
+
+	items := batched.NextN(10)
+
+	if len(items) != 10 {
+		t.Fatalf("Batch size was incorrect: got %d, want %d", len(items), 10)
+	}
+
+	for i, item := range items {
+		want := sequential.Next()
+
+		if item.Value() != want.Value() || item.Statistics.ServesCount != want.Statistics.ServesCount {
+			t.Errorf("Batch diverged from Next at position %d: got %s (%d serves), want %s (%d serves)", i, item.Value(), item.Statistics.ServesCount, want.Value(), want.Statistics.ServesCount)
+		}
+	}
+
+	if !maps.Equal(batched.StatsMap(), sequential.StatsMap()) {
+		t.Errorf("Batch statistics diverged: got %v, want %v", batched.StatsMap(), sequential.StatsMap())
+	}
+
+	if got := batched.NextN(-1); len(got) != 0 {
+		t.Errorf("Expected an empty batch for a negative size, got %d items", len(got))
+	}
+}
+
+func TestDisableAndEnable(t *testing.T) {
+	t.Parallel()
+
+	rr, _ := hqgoroundrobin.New("item1", "item2", "item3")
+
+	if err := rr.Disable("item2"); err != nil {
+		t.Fatalf("Failed to disable an item: %s", err)
+	}
+
+	for range 6 {
+		if item := rr.Next(); item.Value() == "item2" {
+			t.Error("Disabled item was served")
+		}
+	}
+
+	_ = rr.Enable("item2")
+
+	served := make(map[string]bool)
+
+	for range 3 {
+		served[rr.Next().Value()] = true
+	}
+
+	if !served["item2"] {
+		t.Error("Re-enabled item did not rejoin the rotation")
+	}
+
+	for _, value := range rr.Values() {
+		_ = rr.Disable(value)
+	}
+
+	if _, err := rr.NextE(); !errors.Is(err, hqgoroundrobin.ErrNoItems) {
+		t.Errorf("Expected ErrNoItems error, got %v", err)
+	}
+
+	if err := rr.Disable("item4"); !errors.Is(err, hqgoroundrobin.ErrItemNotFound) {
+		t.Errorf("Expected ErrItemNotFound error, got %v", err)
+	}
+}
+
+func TestCooldown(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(0, 0)
+
+	options := hqgoroundrobin.Options{
+		RotateAmount: 1,
+		Now: func() time.Time {
+			return now
+		},
+	}
+
+	rr, _ := hqgoroundrobin.NewWithOptions(options, "item1", "item2", "item3")
+
+	if err := rr.Cooldown("item2", time.Minute); err != nil {
+		t.Fatalf("Failed to cool down an item: %s", err)
+	}
+
+	for range 4 {
+		if item := rr.Next(); item.Value() == "item2" {
+			t.Error("Item was served during its cooldown")
+		}
+	}
+
+	now = now.Add(time.Minute)
+
+	served := make(map[string]bool)
+
+	for range 3 {
+		served[rr.Next().Value()] = true
+	}
+
+	if !served["item2"] {
+		t.Error("Item did not rejoin the rotation after its cooldown elapsed")
+	}
+
+	if err := rr.Cooldown("item4", time.Minute); !errors.Is(err, hqgoroundrobin.ErrItemNotFound) {
+		t.Errorf("Expected ErrItemNotFound error, got %v", err)
+	}
+}
+
+func TestAllEligible(t *testing.T) {
+	t.Parallel()
+
+	rr, _ := hqgoroundrobin.New("item1", "item2", "item3")
+
+	if !rr.AllEligible() || len(rr.IneligibleValues()) != 0 {
+		t.Error("Expected every item to be eligible")
+	}
+
+	_ = rr.Disable("item2")
+
+	if rr.AllEligible() {
+		t.Error("Expected a disabled item to make the round-robin not fully eligible")
+	}
+
+	if got := rr.IneligibleValues(); !slices.Equal(got, []string{"item2"}) {
+		t.Errorf("Ineligible values were incorrect: got %v", got)
+	}
+}
+
+func TestNextAvailable(t *testing.T) {
+	t.Parallel()
+
+	rr, _ := hqgoroundrobin.New("item1", "item2")
+
+	if item, err := rr.NextAvailable(t.Context()); err != nil || item.Value() != "item1" {
+		t.Errorf("Expected item1 to be available, got %s (%v)", item.Value(), err)
+	}
+
+	_ = rr.Cooldown("item1", 20*time.Millisecond)
+	_ = rr.Cooldown("item2", time.Hour)
+
+	item, err := rr.NextAvailable(t.Context())
+	if err != nil || item.Value() != "item1" {
+		t.Errorf("Expected item1 once its cooldown elapsed, got %s (%v)", item.Value(), err)
+	}
+}
+
+func TestNextAvailableCanceled(t *testing.T) {
+	t.Parallel()
+
+	rr, _ := hqgoroundrobin.New("item1")
+
+	_ = rr.Disable("item1")
+
+	ctx, cancel := context.WithCancel(t.Context())
+
+	cancel()
+
+	if _, err := rr.NextAvailable(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled error, got %v", err)
+	}
+}
+
+func TestNextAvailableTimeout(t *testing.T) {
+	t.Parallel()
+
+	rr, _ := hqgoroundrobin.New("item1")
+
+	_ = rr.Cooldown("item1", time.Hour)
+
+	ctx, cancel := context.WithTimeout(t.Context(), 20*time.Millisecond)
+
+	defer cancel()
+
+	if _, err := rr.NextAvailable(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded error, got %v", err)
+	}
+}
+
+func TestAddWithRotate(t *testing.T) {
+	t.Parallel()
+
+	rr := &hqgoroundrobin.RoundRobin{}
+
+	rr.AddWithRotate("item1", 1)
+	rr.AddWithRotate("item2", 2)
+	rr.AddWithRotate("item3", 3)
+
+	want := []string{"item1", "item2", "item2", "item3", "item3", "item3", "item1"}
+
+	got := make([]string, 0, len(want))
+
+	for range want {
+		got = append(got, rr.Next().Value())
+	}
+
+	if !slices.Equal(got, want) {
+		t.Errorf("Per-item rotate amounts were not honored: got %v, want %v", got, want)
+	}
+
+	if order := rr.EffectiveOrder(); len(order) != 6 {
+		t.Errorf("Cycle length did not account for per-item rotate amounts: got %d, want %d", len(order), 6)
+	}
+}
+
+func TestItemHistory(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(0, 0)
+
+	options := hqgoroundrobin.Options{
+		RotateAmount: 1,
+		TrackHistory: 4,
+		Now: func() time.Time {
+			return now
+		},
+	}
+
+	rr, _ := hqgoroundrobin.NewWithOptions(options, "item1", "item2")
+
+	rr.Next()
+
+	now = now.Add(time.Second)
+
+	_ = rr.Disable("item1")
+	_ = rr.Enable("item1")
+	_ = rr.Cooldown("item1", time.Minute)
+
+	history, err := rr.ItemHistory("item1")
+	if err != nil {
+		t.Fatalf("Failed to get the history of an item: %s", err)
+	}
+
+	want := []hqgoroundrobin.HistoryEvent{
+		hqgoroundrobin.HistoryServed,
+		hqgoroundrobin.HistoryDisabled,
+		hqgoroundrobin.HistoryEnabled,
+		hqgoroundrobin.HistoryCooldown,
+	}
+
+	events := make([]hqgoroundrobin.HistoryEvent, 0, len(history))
+
+	for _, entry := range history {
+		events = append(events, entry.Event)
+	}
+
+	if !slices.Equal(events, want) {
+		t.Errorf("Unexpected history: got %v, want %v", events, want)
+	}
+
+	if !history[0].At.Equal(time.Unix(0, 0)) || !history[3].At.Equal(now) {
+		t.Errorf("Unexpected history timestamps: got %v and %v", history[0].At, history[3].At)
+	}
+
+	if history[3].Detail != time.Minute.String() {
+		t.Errorf("Unexpected cooldown detail: got %q, want %q", history[3].Detail, time.Minute.String())
+	}
+
+	history, _ = rr.ItemHistory("item2")
+
+	if len(history) != 1 || history[0].Event != hqgoroundrobin.HistoryAdded {
+		t.Errorf("Unexpected history of an unserved item: got %v", history)
+	}
+
+	if _, err := rr.ItemHistory("item3"); !errors.Is(err, hqgoroundrobin.ErrItemNotFound) {
+		t.Errorf("Expected ErrItemNotFound error, got %v", err)
+	}
+}
+
+func TestClone(t *testing.T) {
+	t.Parallel()
+
+	rr, _ := hqgoroundrobin.New("item1", "item2", "item3")
+
+	rr.Next()
+	rr.Next()
+
+	want := rr.StatsMap()
+
+	clone := rr.Clone()
+
+	for range 100 {
+		clone.Next()
+	}
+
+	clone.Add("item4")
+
+	if got := rr.StatsMap(); !maps.Equal(got, want) {
+		t.Errorf("Serving the clone changed the original statistics: got %v, want %v", got, want)
+	}
+
+	if rr.Contains("item4") {
+		t.Error("Adding to the clone changed the original items")
+	}
+
+	if got := clone.TotalServes(); got != 102 {
+		t.Errorf("Unexpected clone total serves: got %d, want %d", got, 102)
+	}
+
+	if got, want := rr.Next().Value(), "item3"; got != want {
+		t.Errorf("Unexpected next item of the original: got %s, want %s", got, want)
+	}
+}
+
+func TestString(t *testing.T) {
+	t.Parallel()
+
+	rr, _ := hqgoroundrobin.New("item1", "item2", "item3")
+
+	rr.Next()
+
+	want := "RoundRobin(3 items, next=item2, rotateAmount=1, serves=[item1:1 item2:0 item3:0])"
+
+	if got := rr.String(); got != want {
+		t.Errorf("Unexpected summary: got %q, want %q", got, want)
+	}
+}
+
+func TestNextDistinctForKey(t *testing.T) {
+	t.Parallel()
+
+	rr, _ := hqgoroundrobin.New("item1", "item2", "item3", "item4")
+
+	_ = rr.Disable("item4")
+
+	for _, key := range []string{"key1", "key2", "key3"} {
+		var used []string
+
+		for range 3 {
+			item, err := rr.NextDistinctForKey(key, used)
+			if err != nil {
+				t.Fatalf("Failed to serve a distinct item for %s: %s", key, err)
+			}
+
+			if slices.Contains(used, item.Value()) {
+				t.Errorf("Item %s was served twice for %s", item.Value(), key)
+			}
+
+			used = append(used, item.Value())
+		}
+
+		if _, err := rr.NextDistinctForKey(key, used); !errors.Is(err, hqgoroundrobin.ErrNoDistinctItems) {
+			t.Errorf("Expected ErrNoDistinctItems error, got %v", err)
+		}
+
+		first, _ := rr.NextDistinctForKey(key, nil)
+
+		if first.Value() != used[0] {
+			t.Errorf("Unstable mapping for %s: got %s, want %s", key, first.Value(), used[0])
+		}
+	}
+}
+
+func TestMutateEach(t *testing.T) {
+	t.Parallel()
+
+	rr, _ := hqgoroundrobin.New("keep1", "drop1", "keep2", "drop2", "bench")
+
+	rr.Next()
+
+	rr.MutateEach(func(item hqgoroundrobin.Item) hqgoroundrobin.Action {
+		switch {
+		case strings.HasPrefix(item.Value(), "drop"):
+			return hqgoroundrobin.ActionRemove
+		case item.Value() == "bench":
+			return hqgoroundrobin.ActionDisable
+		default:
+			return hqgoroundrobin.ActionKeep
+		}
+	})
+
+	if got, want := rr.Values(), []string{"keep1", "keep2", "bench"}; !slices.Equal(got, want) {
+		t.Errorf("Unexpected values: got %v, want %v", got, want)
+	}
+
+	if got, want := rr.IneligibleValues(), []string{"bench"}; !slices.Equal(got, want) {
+		t.Errorf("Unexpected ineligible values: got %v, want %v", got, want)
+	}
+
+	if got := rr.StatsMap()["keep1"]; got != 1 {
+		t.Errorf("Unexpected serves for keep1: got %d, want %d", got, 1)
+	}
+
+	if got, want := rr.Next().Value(), "keep2"; got != want {
+		t.Errorf("Unexpected next item: got %s, want %s", got, want)
+	}
+}
+
+func TestServeHistogram(t *testing.T) {
+	t.Parallel()
+
+	rr, _ := hqgoroundrobin.New("item1", "item2", "item3", "item4", "item5", "item6")
+
+	_ = rr.SetStats(map[string]int32{"item1": 0, "item2": 1, "item3": 2, "item4": 3, "item5": 9, "item6": 10})
+
+	if got, want := rr.ServeHistogram(2), []int{4, 2}; !slices.Equal(got, want) {
+		t.Errorf("Unexpected histogram: got %v, want %v", got, want)
+	}
+
+	if got, want := rr.ServeHistogram(5), []int{2, 2, 0, 0, 2}; !slices.Equal(got, want) {
+		t.Errorf("Unexpected histogram: got %v, want %v", got, want)
+	}
+
+	rr.Reset()
+
+	if got, want := rr.ServeHistogram(3), []int{6, 0, 0}; !slices.Equal(got, want) {
+		t.Errorf("Unexpected histogram of equal serve counts: got %v, want %v", got, want)
+	}
+
+	if got := rr.ServeHistogram(0); got != nil {
+		t.Errorf("Unexpected histogram for zero buckets: got %v", got)
+	}
+}
+
+func TestAddWithMaxServes(t *testing.T) {
+	t.Parallel()
+
+	rr := &hqgoroundrobin.RoundRobin{Options: hqgoroundrobin.DefaultOptions}
+
+	rr.AddWithMaxServes("token1", 3)
+	rr.AddWithMaxServes("token2", 3)
+
+	serves := 0
+
+	for {
+		if _, err := rr.NextE(); err != nil {
+			if !errors.Is(err, hqgoroundrobin.ErrNoItems) {
+				t.Errorf("Expected ErrNoItems error, got %v", err)
+			}
+
+			break
+		}
+
+		serves++
+
+		if serves > 6 {
+			t.Fatal("Exhausted items kept being served")
+		}
+	}
+
+	if serves != 6 {
+		t.Errorf("Unexpected total serves before exhaustion: got %d, want %d", serves, 6)
+	}
+
+	for value, count := range rr.Stats() {
+		if count != 3 {
+			t.Errorf("Unexpected serves for %s: got %d, want %d", value, count, 3)
+		}
+	}
+}
+
+func TestQuotaWindow(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(0, 0)
+
+	options := hqgoroundrobin.Options{
+		RotateAmount:   1,
+		QuotaPerWindow: 3,
+		QuotaWindow:    time.Minute,
+		Now: func() time.Time {
+			return now
+		},
+	}
+
+	rr, _ := hqgoroundrobin.NewWithOptions(options, "item1", "item2")
+
+	for range 2 {
+		if _, err := rr.NextE(); err != nil {
+			t.Fatalf("Failed to serve within the quota: %s", err)
+		}
+	}
+
+	now = now.Add(30 * time.Second)
+
+	if _, err := rr.NextE(); err != nil {
+		t.Fatalf("Failed to serve within the quota: %s", err)
+	}
+
+	if _, err := rr.NextE(); !errors.Is(err, hqgoroundrobin.ErrQuotaExceeded) {
+		t.Errorf("Expected ErrQuotaExceeded error, got %v", err)
+	}
+
+	now = now.Add(30 * time.Second)
+
+	for range 2 {
+		if _, err := rr.NextE(); err != nil {
+			t.Errorf("Failed to serve after the window slid: %s", err)
+		}
+	}
+
+	if _, err := rr.NextE(); !errors.Is(err, hqgoroundrobin.ErrQuotaExceeded) {
+		t.Errorf("Expected ErrQuotaExceeded error, got %v", err)
+	}
+
+	if got := rr.TotalServes(); got != 5 {
+		t.Errorf("Unexpected total serves: got %d, want %d", got, 5)
+	}
+}
+
+func TestIter(t *testing.T) {
+	t.Parallel()
+
+	rr, _ := hqgoroundrobin.New("item1", "item2", "item3")
+
+	values := make([]string, 0, 5)
+
+	for item := range rr.Iter(5) {
+		values = append(values, item.Value())
+	}
+
+	if want := []string{"item1", "item2", "item3", "item1", "item2"}; !slices.Equal(values, want) {
+		t.Errorf("Unexpected items: got %v, want %v", values, want)
+	}
+
+	rr.Reset()
+
+	consumed := 0
+
+	for range rr.Iter(5) {
+		consumed++
+
+		if consumed == 2 {
+			break
+		}
+	}
+
+	if got := rr.TotalServes(); got != 2 {
+		t.Errorf("Unexpected serves after breaking early: got %d, want %d", got, 2)
+	}
+}
+
+func TestAddWeightedBulk(t *testing.T) {
+	t.Parallel()
+
+	rr, _ := hqgoroundrobin.New("item1")
+
+	weights := map[string]int{"item1": 5, "item2": 2, "item3": 3}
+
+	rr.AddWeightedBulk(weights)
+
+	if got := rr.Len(); got != 3 {
+		t.Errorf("Unexpected length: got %d, want %d", got, 3)
+	}
+
+	// item1 was already present, so it keeps its weight.
+	weights["item1"] = 1
+
+	for _, item := range rr.Items() {
+		if got, want := item.Weight(), weights[item.Value()]; got != want {
+			t.Errorf("Unexpected weight for %s: got %d, want %d", item.Value(), got, want)
+		}
+	}
+
+	for range 60 {
+		rr.Next()
+	}
+
+	for value, serves := range rr.Stats() {
+		if want := int32(weights[value] * 10); serves != want {
+			t.Errorf("Unexpected serves for %s: got %d, want %d", value, serves, want)
+		}
+	}
+}
+
+func TestEligibleLen(t *testing.T) {
+	t.Parallel()
+
+	rr, _ := hqgoroundrobin.New("item1", "item2", "item3", "item4")
+
+	rr.AddWithMaxServes("item5", 1)
+
+	_, _ = rr.Serve("item5")
+	_ = rr.Disable("item2")
+	_ = rr.Cooldown("item3", time.Hour)
+
+	if got := rr.EligibleLen(); got != 2 {
+		t.Errorf("Unexpected eligible length: got %d, want %d", got, 2)
+	}
+
+	if got, want := rr.EligibleLen(), len(rr.EligibleItems()); got != want {
+		t.Errorf("Eligible length disagrees with the eligible items: got %d, want %d", got, want)
+	}
+
+	values := make([]string, 0, 2)
+
+	for _, item := range rr.EligibleItems() {
+		values = append(values, item.Value())
+	}
+
+	if want := []string{"item1", "item4"}; !slices.Equal(values, want) {
+		t.Errorf("Unexpected eligible items: got %v, want %v", values, want)
+	}
+}
+
+func TestNextAfterItemsBecomeEmpty(t *testing.T) {
+	t.Parallel()
+
+	rr, _ := hqgoroundrobin.New("item1", "item2")
+
+	rr.Next()
+
+	rr.MutateEach(func(hqgoroundrobin.Item) hqgoroundrobin.Action {
+		return hqgoroundrobin.ActionRemove
+	})
+
+	for _, pool := range []*hqgoroundrobin.RoundRobin{rr, {}} {
+		if item := pool.Next(); item.Value() != "" {
+			t.Errorf("Expected a zero item, got %s", item.Value())
+		}
+
+		if item := pool.Peek(); item.Value() != "" {
+			t.Errorf("Expected a zero peeked item, got %s", item.Value())
+		}
+
+		if _, rotated := pool.NextInfo(); rotated {
+			t.Error("Expected no rotation without items")
+		}
+
+		if _, rank := pool.NextRanked(); rank != 0 {
+			t.Errorf("Unexpected rank without items: got %d, want %d", rank, 0)
+		}
+
+		if got := pool.NextN(3); len(got) != 0 {
+			t.Errorf("Expected no items, got %d", len(got))
+		}
+
+		if got := pool.Trace(3); len(got) != 0 {
+			t.Errorf("Expected an empty trace, got %v", got)
+		}
+	}
+}
+
+func TestContainsDuringSetValues(t *testing.T) {
+	t.Parallel()
+
+	values := make([]string, 0, 100)
+
+	for i := range 100 {
+		values = append(values, "item"+strconv.Itoa(i))
+	}
+
+	rr, _ := hqgoroundrobin.New(values...)
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		for range 1000 {
+			_ = rr.SetValues(values)
+		}
+	}()
+
+	for {
+		select {
+		case <-done:
+			if rr.Contains("item100") {
+				t.Error("Unexpected value after replacing the items")
+			}
+
+			return
+		default:
+		}
+
+		for _, value := range values {
+			if !rr.Contains(value) {
+				t.Fatalf("Retained value %s was reported missing while the items were replaced", value)
+			}
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package roundrobin
+
+import (
+	"errors"
+	"iter"
+)
+
+// Sealed is a read-only view of a round-robin. It serves items and exposes the round-robin's state, sharing its
+// rotation and statistics, but every method that would change the membership or weights returns ErrImmutable.
+// This protects shared, configuration-loaded pools from being modified by code that should only consume them.
+type Sealed struct {
+	// rr is the sealed round-robin.
+	rr *RoundRobin
+}
+
+// Next retrieves the next item in the round-robin order, like RoundRobin.Next.
+func (s *Sealed) Next() (item Item) {
+	return s.rr.Next()
+}
+
+// NextE retrieves the next item in the round-robin order, like RoundRobin.NextE.
+func (s *Sealed) NextE() (item Item, err error) {
+	return s.rr.NextE()
+}
+
+// Peek returns the item the next call to Next would serve, without serving it, like RoundRobin.Peek.
+func (s *Sealed) Peek() (item Item) {
+	return s.rr.Peek()
+}
+
+// Values returns a copy of the item values in rotation order, like RoundRobin.Values.
+func (s *Sealed) Values() (values []string) {
+	return s.rr.Values()
+}
+
+// Stats returns an iterator over the value and serve count of each item, like RoundRobin.Stats.
+func (s *Sealed) Stats() iter.Seq2[string, int32] {
+	return s.rr.Stats()
+}
+
+// TotalServes returns the sum of the serve counts of all items, like RoundRobin.TotalServes.
+func (s *Sealed) TotalServes() (total int64) {
+	return s.rr.TotalServes()
+}
+
+// Add always returns ErrImmutable, since a sealed round-robin cannot be modified.
+func (s *Sealed) Add(_ ...string) (err error) {
+	return ErrImmutable
+}
+
+// AddWeighted always returns ErrImmutable, since a sealed round-robin cannot be modified.
+func (s *Sealed) AddWeighted(_ string, _ int) (err error) {
+	return ErrImmutable
+}
+
+// SetValues always returns ErrImmutable, since a sealed round-robin cannot be modified.
+func (s *Sealed) SetValues(_ []string) (err error) {
+	return ErrImmutable
+}
+
+// Sealed returns a read-only view of the round-robin. The view shares the rotation and statistics of the
+// round-robin, which remains modifiable through the original, so only the view should be handed out.
+func (r *RoundRobin) Sealed() (sealed *Sealed) {
+	return &Sealed{
+		rr: r,
+	}
+}
+
+// ErrImmutable indicates an attempt to modify a sealed round-robin.
+var ErrImmutable = errors.New("round-robin is immutable")
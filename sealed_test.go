@@ -0,0 +1,34 @@
+package roundrobin_test
+
+import (
+	"errors"
+	"testing"
+
+	hqgoroundrobin "github.com/hueristiq/hq-go-roundrobin"
+)
+
+func TestSealed(t *testing.T) {
+	t.Parallel()
+
+	rr, _ := hqgoroundrobin.New("item1", "item2")
+
+	sealed := rr.Sealed()
+
+	if err := sealed.Add("item3"); !errors.Is(err, hqgoroundrobin.ErrImmutable) {
+		t.Errorf("Expected ErrImmutable error, got %v", err)
+	}
+
+	if err := sealed.SetValues([]string{"item3"}); !errors.Is(err, hqgoroundrobin.ErrImmutable) {
+		t.Errorf("Expected ErrImmutable error, got %v", err)
+	}
+
+	if got := len(sealed.Values()); got != 2 {
+		t.Errorf("Sealed round-robin was modified: got %d items, want %d", got, 2)
+	}
+
+	for _, want := range []string{"item1", "item2", "item1"} {
+		if item := sealed.Next(); item.Value() != want {
+			t.Errorf("Sealed round-robin served the wrong item: got %s, want %s", item.Value(), want)
+		}
+	}
+}
@@ -0,0 +1,232 @@
+package roundrobin
+
+import (
+	"math/rand/v2"
+	"sync/atomic"
+)
+
+// Strategy picks the item each serve goes to. Setting Options.Strategy swaps the selection algorithm without
+// changing call sites: the round-robin still handles locking, eligibility bookkeeping and statistics, and only
+// delegates the choice of the index.
+type Strategy interface {
+	// Select returns the index in items of the item to serve next, or -1 if none should be served. Only
+	// eligible items may be selected. items is the round-robin's own slice and must not be modified or retained.
+	Select(items []Item, state *SelectState) (index int)
+}
+
+// Ranker is implemented by strategies that order the items by a selection criterion, letting NextRanked report
+// how the served item ranked.
+type Ranker interface {
+	// Rank returns the 1-based rank of the item at the given index among the eligible items, 1 being the most
+	// preferred. It is called with the same arguments as the Select call that picked the item, before it is served.
+	Rank(items []Item, state *SelectState, index int) (rank int)
+}
+
+// Cloner is implemented by strategies holding mutable state, such as a seeded source of randomness. Copies of a
+// round-robin, like those made by Clone, SubPool, Trace and EffectiveOrder, get a clone of such a strategy, and Peek
+// selects with a throwaway clone, so that neither shares or advances the state of the original.
+type Cloner interface {
+	// CloneStrategy returns an independent copy of the strategy and its current state.
+	CloneStrategy() (strategy Strategy)
+}
+
+// SelectState describes the rotation when a Strategy is asked to select an item. It is only valid during the
+// call to Select.
+type SelectState struct {
+	// Current is the index of the item currently being served, or -1 if nothing has been served yet.
+	Current int
+	// Serves is the number of consecutive serves the current item got in its current turn.
+	Serves int
+	// RotateAmount is the number of consecutive serves the current item gets before its turn ends.
+	RotateAmount int
+	// Weighted reports whether any item has a weight other than 1.
+	Weighted bool
+	// Eligible reports whether the item at the given index may currently be served.
+	Eligible func(index int) (ok bool)
+}
+
+// StrategyRoundRobin is the default strategy: each item is served its rotate amount consecutive times before the
+// rotation advances to the next eligible item, using smooth weighted round-robin selection if any item is
+// weighted.
+type StrategyRoundRobin struct{}
+
+// Select implements Strategy.
+func (StrategyRoundRobin) Select(items []Item, state *SelectState) (index int) {
+	index = state.Current
+
+	// Keep serving the current item until it has been served its rotate amount consecutive times, unless it became
+	// ineligible. Weighted selection also picks the very first item instead of starting with the one under the
+	// cursor.
+	if index >= 0 && state.Eligible(index) && state.Serves < state.RotateAmount && (!state.Weighted || state.Serves > 0) {
+		return
+	}
+
+	if state.Weighted {
+		return selectWeighted(items, state)
+	}
+
+	// Rotate to the next eligible item. The cursor is wrapped within the bounds of the items slice, so it can
+	// neither overflow nor skew the rotation.
+	for range len(items) {
+		index = (index + 1) % len(items)
+
+		if state.Eligible(index) {
+			return
+		}
+	}
+
+	return -1
+}
+
+// selectWeighted returns the index of the eligible item smooth weighted round-robin selection picks next: the
+// item with the highest running weight once every eligible item's weight has been added to it, preferring the
+// earliest item on ties. It returns -1 if no item is eligible.
+func selectWeighted(items []Item, state *SelectState) (index int) {
+	index = -1
+
+	for i := range items {
+		if !state.Eligible(i) {
+			continue
+		}
+
+		if index < 0 || items[i].currentWeight+items[i].weight > items[index].currentWeight+items[index].weight {
+			index = i
+		}
+	}
+
+	return
+}
+
+// StrategyRandom serves a random eligible item for each turn, with a probability proportional to its weight. Like
+// StrategyRoundRobin it keeps serving an item for its rotate amount consecutive times. The zero value draws from
+// the global source; NewStrategyRandomSource creates one drawing from a caller-provided source, and
+// NewStrategyRandom one drawing from a seeded PCG source. A strategy drawing from a *rand.PCG or *rand.ChaCha8
+// source is cloned along with the state of its source, so Peek and the projections of Trace see exactly the choices
+// the following serves make, without consuming them.
+type StrategyRandom struct {
+	// source is the caller-provided source of randomness, or nil to use the global source.
+	source rand.Source
+	// rand draws random numbers from source.
+	rand *rand.Rand
+}
+
+// NewStrategyRandomSource returns a StrategyRandom drawing from the given source, or from the global source if it is
+// nil. The source is only used with the mutex of the round-robin held, so it must not be shared between
+// round-robins or used elsewhere. Copies made by Clone and SubPool get their own copy of a *rand.PCG or
+// *rand.ChaCha8 source; copies of a strategy with any other source, which cannot be copied, draw from the global
+// source instead.
+func NewStrategyRandomSource(source rand.Source) (strategy StrategyRandom) {
+	if source == nil {
+		return
+	}
+
+	return StrategyRandom{
+		source: source,
+		rand:   rand.New(source),
+	}
+}
+
+// NewStrategyRandom returns a StrategyRandom drawing from a PCG source seeded with the given seeds, like
+// NewStrategyRandomSource, so that round-robins with identically seeded strategies serve the same sequence.
+func NewStrategyRandom(seed1, seed2 uint64) (strategy StrategyRandom) {
+	return NewStrategyRandomSource(rand.NewPCG(seed1, seed2))
+}
+
+// CloneStrategy implements Cloner: the copy continues from the current state of the source independently of the
+// original. Sources other than *rand.PCG and *rand.ChaCha8 cannot be copied, so their copy draws from the global
+// source.
+func (s StrategyRandom) CloneStrategy() (strategy Strategy) {
+	switch source := s.source.(type) {
+	case *rand.PCG:
+		clone := *source
+
+		return NewStrategyRandomSource(&clone)
+	case *rand.ChaCha8:
+		clone := *source
+
+		return NewStrategyRandomSource(&clone)
+	default:
+		return StrategyRandom{}
+	}
+}
+
+// Select implements Strategy.
+func (s StrategyRandom) Select(items []Item, state *SelectState) (index int) {
+	index = state.Current
+
+	if index >= 0 && state.Eligible(index) && state.Serves > 0 && state.Serves < state.RotateAmount {
+		return
+	}
+
+	index = -1
+
+	total := 0
+
+	// Weighted reservoir sampling picks each eligible item with a probability proportional to its weight in a
+	// single pass.
+	for i := range items {
+		if !state.Eligible(i) {
+			continue
+		}
+
+		total += items[i].weight
+
+		if s.intN(total) < items[i].weight {
+			index = i
+		}
+	}
+
+	return
+}
+
+// intN returns a random number in [0, n) from the seeded source, or from the global source if unseeded.
+func (s StrategyRandom) intN(n int) (random int) {
+	if s.rand != nil {
+		return s.rand.IntN(n)
+	}
+
+	return rand.IntN(n)
+}
+
+// StrategyLeastServed always serves the eligible item with the lowest serve count, preferring the earliest item on
+// ties. It keeps the serve counts as balanced as possible, letting items added mid-run catch up with the others,
+// and ignores weights and rotate amounts.
+type StrategyLeastServed struct{}
+
+// Select implements Strategy.
+func (StrategyLeastServed) Select(items []Item, state *SelectState) (index int) {
+	index = -1
+
+	var least int32
+
+	for i := range items {
+		if !state.Eligible(i) {
+			continue
+		}
+
+		if serves := atomic.LoadInt32(&items[i].Statistics.ServesCount); index < 0 || serves < least {
+			index, least = i, serves
+		}
+	}
+
+	return
+}
+
+// Rank implements Ranker, ranking items by ascending serve count and then by index.
+func (StrategyLeastServed) Rank(items []Item, state *SelectState, index int) (rank int) {
+	rank = 1
+
+	serves := atomic.LoadInt32(&items[index].Statistics.ServesCount)
+
+	for i := range items {
+		if i == index || !state.Eligible(i) {
+			continue
+		}
+
+		if other := atomic.LoadInt32(&items[i].Statistics.ServesCount); other < serves || (other == serves && i < index) {
+			rank++
+		}
+	}
+
+	return
+}
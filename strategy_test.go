@@ -0,0 +1,262 @@
+package roundrobin_test
+
+import (
+	"math/rand/v2"
+	"runtime"
+	"slices"
+	"testing"
+	"time"
+
+	hqgoroundrobin "github.com/hueristiq/hq-go-roundrobin"
+)
+
+func TestStrategyRoundRobin(t *testing.T) {
+	t.Parallel()
+
+	for _, rotateAmount := range []int32{1, 2} {
+		options := hqgoroundrobin.Options{RotateAmount: rotateAmount}
+
+		defaulted, _ := hqgoroundrobin.NewWithOptions(options, "item1", "item2", "item3")
+
+		options.Strategy = hqgoroundrobin.StrategyRoundRobin{}
+
+		explicit, _ := hqgoroundrobin.NewWithOptions(options, "item1", "item2", "item3")
+
+		for _, rr := range []*hqgoroundrobin.RoundRobin{defaulted, explicit} {
+			rr.AddWeighted("item4", 3)
+
+			_ = rr.Disable("item2")
+		}
+
+		for range 24 {
+			if got, want := explicit.Next().Value(), defaulted.Next().Value(); got != want {
+				t.Errorf("Unexpected item for RotateAmount %d: got %s, want %s", rotateAmount, got, want)
+			}
+		}
+	}
+}
+
+func TestStrategyRandom(t *testing.T) {
+	t.Parallel()
+
+	values := []string{"item1", "item2", "item3", "item4"}
+
+	rr, _ := hqgoroundrobin.NewWithOptions(hqgoroundrobin.Options{RotateAmount: 1, Strategy: hqgoroundrobin.StrategyRandom{}}, values...)
+
+	_ = rr.Disable("item4")
+
+	served := make(map[string]bool)
+
+	for range 1000 {
+		served[rr.Next().Value()] = true
+	}
+
+	for _, value := range values[:3] {
+		if !served[value] {
+			t.Errorf("Item %s was never served", value)
+		}
+	}
+
+	if served["item4"] {
+		t.Error("Disabled item was served")
+	}
+
+	if got := rr.TotalServes(); got != 1000 {
+		t.Errorf("Unexpected total serves: got %d, want %d", got, 1000)
+	}
+}
+
+// countingSource is a caller-provided rand.Source that is neither a *rand.PCG nor a *rand.ChaCha8.
+type countingSource struct {
+	state uint64
+}
+
+func (s *countingSource) Uint64() (value uint64) {
+	s.state += 0x9e3779b97f4a7c15
+
+	return s.state ^ s.state>>31
+}
+
+func TestStrategyRandomSeeded(t *testing.T) {
+	t.Parallel()
+
+	strategies := map[string]func() hqgoroundrobin.StrategyRandom{
+		"seeds": func() hqgoroundrobin.StrategyRandom {
+			return hqgoroundrobin.NewStrategyRandom(1, 2)
+		},
+		"ChaCha8": func() hqgoroundrobin.StrategyRandom {
+			return hqgoroundrobin.NewStrategyRandomSource(rand.NewChaCha8([32]byte{1, 2}))
+		},
+		"custom": func() hqgoroundrobin.StrategyRandom {
+			return hqgoroundrobin.NewStrategyRandomSource(&countingSource{state: 1})
+		},
+	}
+
+	for name, strategy := range strategies {
+		sequence := func() (values []string) {
+			options := hqgoroundrobin.Options{
+				RotateAmount: 1,
+				Strategy:     strategy(),
+			}
+
+			rr, _ := hqgoroundrobin.NewWithOptions(options, "item1", "item2", "item3", "item4")
+
+			for range 50 {
+				values = append(values, rr.Next().Value())
+			}
+
+			return
+		}
+
+		if first, second := sequence(), sequence(); !slices.Equal(first, second) {
+			t.Errorf("Identically seeded instances with %s source diverged: got %v and %v", name, first, second)
+		}
+	}
+}
+
+func TestStrategyRandomProjections(t *testing.T) {
+	t.Parallel()
+
+	strategies := map[string]func() hqgoroundrobin.StrategyRandom{
+		"PCG": func() hqgoroundrobin.StrategyRandom {
+			return hqgoroundrobin.NewStrategyRandom(3, 4)
+		},
+		"ChaCha8": func() hqgoroundrobin.StrategyRandom {
+			return hqgoroundrobin.NewStrategyRandomSource(rand.NewChaCha8([32]byte{3, 4}))
+		},
+	}
+
+	for name, strategy := range strategies {
+		newSeeded := func() *hqgoroundrobin.RoundRobin {
+			options := hqgoroundrobin.Options{
+				RotateAmount: 1,
+				Strategy:     strategy(),
+			}
+
+			rr, _ := hqgoroundrobin.NewWithOptions(options, "item1", "item2", "item3", "item4")
+
+			return rr
+		}
+
+		projected, reference := newSeeded(), newSeeded()
+
+		for i := range 50 {
+			peeked := projected.Peek()
+			trace := projected.Trace(20)
+
+			_ = projected.String()
+			_ = projected.EffectiveOrder()
+
+			projected.Clone().Next()
+
+			got, want := projected.Next().Value(), reference.Next().Value()
+			if got != want {
+				t.Fatalf("Read-only calls changed the %s sequence at serve %d: got %s, want %s", name, i+1, got, want)
+			}
+
+			if peeked.Value() != got || trace[0] != got {
+				t.Errorf("Projections disagreed with %s serve %d: peeked %s and traced %s, served %s", name, i+1, peeked.Value(), trace[0], got)
+			}
+		}
+	}
+}
+
+func TestStrategyRandomConcurrentTrace(t *testing.T) {
+	t.Parallel()
+
+	options := hqgoroundrobin.Options{
+		RotateAmount: 1,
+		Strategy:     hqgoroundrobin.NewStrategyRandom(5, 6),
+	}
+
+	rr, _ := hqgoroundrobin.NewWithOptions(options, "item1", "item2", "item3")
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		for range 100 {
+			rr.Trace(10)
+
+			runtime.Gosched()
+		}
+	}()
+
+	// Yielding interleaves the serves with the projections even on a single CPU.
+	for range 100 {
+		rr.Next()
+
+		runtime.Gosched()
+	}
+
+	<-done
+}
+
+func TestStrategyLeastServed(t *testing.T) {
+	t.Parallel()
+
+	rr, _ := hqgoroundrobin.NewWithOptions(hqgoroundrobin.Options{RotateAmount: 1, Strategy: hqgoroundrobin.StrategyLeastServed{}}, "item1", "item2", "item3")
+
+	for range 9 {
+		rr.Next()
+	}
+
+	rr.Add("item4")
+
+	for i := range 3 {
+		if got := rr.Next().Value(); got != "item4" {
+			t.Errorf("Unexpected item on serve %d after adding: got %s, want %s", i+1, got, "item4")
+		}
+	}
+
+	for range 8 {
+		rr.Next()
+	}
+
+	for value, serves := range rr.Stats() {
+		if serves != 5 {
+			t.Errorf("Unexpected serves for %s: got %d, want %d", value, serves, 5)
+		}
+	}
+}
+
+func TestNextRanked(t *testing.T) {
+	t.Parallel()
+
+	rr, _ := hqgoroundrobin.NewWithOptions(hqgoroundrobin.Options{RotateAmount: 1, Strategy: hqgoroundrobin.StrategyLeastServed{}}, "item1", "item2", "item3")
+
+	_ = rr.SetStats(map[string]int32{"item1": 5, "item2": 2, "item3": 7})
+
+	for range 6 {
+		least, _ := rr.LeastServed()
+
+		item, rank := rr.NextRanked()
+
+		if rank != 1 {
+			t.Errorf("Unexpected rank: got %d, want %d", rank, 1)
+		}
+
+		if item.Value() != least.Value() {
+			t.Errorf("Served item was not the least served: got %s, want %s", item.Value(), least.Value())
+		}
+	}
+
+	roundRobin, _ := hqgoroundrobin.New("item1", "item2")
+
+	if _, rank := roundRobin.NextRanked(); rank != 1 {
+		t.Errorf("Unexpected rank under round-robin: got %d, want %d", rank, 1)
+	}
+}
+
+func TestNextRankedQuota(t *testing.T) {
+	t.Parallel()
+
+	rr, _ := hqgoroundrobin.NewWithOptions(hqgoroundrobin.Options{RotateAmount: 1, QuotaPerWindow: 1, QuotaWindow: time.Hour}, "item1")
+
+	rr.Next()
+
+	if item, rank := rr.NextRanked(); rank != 0 || item.Value() != "" {
+		t.Errorf("Expected no serve beyond the quota, got %s with rank %d", item.Value(), rank)
+	}
+}
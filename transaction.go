@@ -0,0 +1,150 @@
+package roundrobin
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"sync/atomic"
+)
+
+// lastID is the most recently assigned round-robin ID.
+var lastID atomic.Uint64
+
+// Tx changes the membership of several round-robins atomically. It is only valid during the call to Transact that
+// created it.
+type Tx struct {
+	// snapshots maps each round-robin of the transaction to a copy of its state when the transaction started.
+	snapshots map[*RoundRobin]*RoundRobin
+}
+
+// Add inserts values into rr like RoundRobin.AddErr. It returns ErrNotInTransaction if rr is not part of the
+// transaction.
+func (tx *Tx) Add(rr *RoundRobin, values ...string) (err error) {
+	if _, ok := tx.snapshots[rr]; !ok {
+		err = ErrNotInTransaction
+
+		return
+	}
+
+	return rr.addErr(values...)
+}
+
+// AddWeighted inserts a value with the given weight into rr like RoundRobin.AddWeighted. Like Add, it returns
+// ErrDuplicateItem instead if Options.ErrorOnDuplicate is set and the value is already present. It returns
+// ErrNotInTransaction if rr is not part of the transaction.
+func (tx *Tx) AddWeighted(rr *RoundRobin, value string, weight int) (err error) {
+	if _, ok := tx.snapshots[rr]; !ok {
+		err = ErrNotInTransaction
+
+		return
+	}
+
+	if !rr.addWeighted(value, weight) && rr.Options.ErrorOnDuplicate {
+		err = fmt.Errorf("%w: %s", ErrDuplicateItem, value)
+	}
+
+	return
+}
+
+// Remove removes the item with the given value from rr, which keeps rotating from its current item if that
+// remains. It returns ErrNotInTransaction if rr is not part of the transaction and ErrItemNotFound if no such item
+// exists.
+func (tx *Tx) Remove(rr *RoundRobin, value string) (err error) {
+	if _, ok := tx.snapshots[rr]; !ok {
+		err = ErrNotInTransaction
+
+		return
+	}
+
+	index := rr.indexOf(value)
+	if index < 0 {
+		err = ErrItemNotFound
+
+		return
+	}
+
+	values := make([]string, 0, len(rr.items)-1)
+
+	for i := range rr.items {
+		if i != index {
+			values = append(values, rr.items[i].value)
+		}
+	}
+
+	rr.setValues(values)
+
+	return
+}
+
+// Transact runs fn with all the given round-robins locked, so that coordinated membership changes made through the
+// Tx apply to all of them or none: if fn returns an error or panics, every round-robin is rolled back to its state
+// before the transaction, and the error is returned or the panic resumed once the round-robins are unlocked. The
+// round-robins are locked in a fixed order, so concurrent transactions over overlapping pools cannot deadlock. Nil
+// entries of pools are ignored. fn must not call methods of the round-robins directly, as their mutexes are held.
+func Transact(pools []*RoundRobin, fn func(tx *Tx) (err error)) (err error) {
+	pools = slices.DeleteFunc(slices.Clone(pools), func(rr *RoundRobin) bool {
+		return rr == nil
+	})
+
+	slices.SortFunc(pools, func(a, b *RoundRobin) int {
+		return cmp.Compare(a.identity(), b.identity())
+	})
+
+	pools = slices.Compact(pools)
+
+	tx := &Tx{
+		snapshots: make(map[*RoundRobin]*RoundRobin, len(pools)),
+	}
+
+	for _, rr := range pools {
+		rr.mutex.Lock()
+
+		tx.snapshots[rr] = rr.clone()
+	}
+
+	defer func() {
+		recovered := recover()
+
+		if err != nil || recovered != nil {
+			for rr, snapshot := range tx.snapshots {
+				rr.restore(snapshot)
+			}
+		}
+
+		for _, rr := range slices.Backward(pools) {
+			rr.mutex.Unlock()
+		}
+
+		if recovered != nil {
+			panic(recovered)
+		}
+	}()
+
+	err = fn(tx)
+
+	return
+}
+
+// restore rolls the items and rotation of the round-robin back to the given snapshot taken by clone. It must be
+// called with the mutex held.
+func (r *RoundRobin) restore(snapshot *RoundRobin) {
+	r.items = snapshot.items
+	r.nextItemIndex = snapshot.nextItemIndex
+	r.currentItemServesCount = snapshot.currentItemServesCount
+	r.histories = snapshot.histories
+	r.quotaServes = snapshot.quotaServes
+	r.weighted = snapshot.weighted
+
+	r.syncItemsMap()
+}
+
+// identity returns the ID of the round-robin, assigning the next free one on first use.
+func (r *RoundRobin) identity() (id uint64) {
+	if id = r.id.Load(); id != 0 {
+		return
+	}
+
+	r.id.CompareAndSwap(0, lastID.Add(1))
+
+	return r.id.Load()
+}
@@ -0,0 +1,167 @@
+package roundrobin_test
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+	"testing"
+
+	hqgoroundrobin "github.com/hueristiq/hq-go-roundrobin"
+)
+
+func TestTransact(t *testing.T) {
+	t.Parallel()
+
+	shard1, _ := hqgoroundrobin.New("item1", "item2")
+	shard2, _ := hqgoroundrobin.New("item1", "item2")
+	shard3, _ := hqgoroundrobin.NewWithOptions(hqgoroundrobin.Options{RotateAmount: 1, ErrorOnDuplicate: true}, "item3")
+
+	shard1.Next()
+
+	pools := []*hqgoroundrobin.RoundRobin{shard1, shard2, shard3}
+
+	err := hqgoroundrobin.Transact(pools, func(tx *hqgoroundrobin.Tx) error {
+		for _, rr := range pools {
+			if err := tx.Remove(rr, "item1"); err != nil && !errors.Is(err, hqgoroundrobin.ErrItemNotFound) {
+				return err
+			}
+
+			if err := tx.Add(rr, "item3"); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if !errors.Is(err, hqgoroundrobin.ErrDuplicateItem) {
+		t.Fatalf("Expected ErrDuplicateItem error, got %v", err)
+	}
+
+	for i, want := range [][]string{{"item1", "item2"}, {"item1", "item2"}, {"item3"}} {
+		if got := pools[i].Values(); !slices.Equal(got, want) {
+			t.Errorf("Pool %d was modified by a failed transaction: got %v, want %v", i+1, got, want)
+		}
+	}
+
+	if got, want := shard1.Next().Value(), "item2"; got != want {
+		t.Errorf("Unexpected next item after rollback: got %s, want %s", got, want)
+	}
+
+	err = hqgoroundrobin.Transact(pools[:2], func(tx *hqgoroundrobin.Tx) error {
+		for _, rr := range pools[:2] {
+			if err := tx.Add(rr, "item3"); err != nil {
+				return err
+			}
+		}
+
+		return tx.Add(shard3, "item4")
+	})
+	if !errors.Is(err, hqgoroundrobin.ErrNotInTransaction) {
+		t.Errorf("Expected ErrNotInTransaction error, got %v", err)
+	}
+
+	err = hqgoroundrobin.Transact([]*hqgoroundrobin.RoundRobin{shard1, shard2, shard1}, func(tx *hqgoroundrobin.Tx) error {
+		return tx.Add(shard1, "item3")
+	})
+	if err != nil {
+		t.Fatalf("Failed to commit a transaction: %s", err)
+	}
+
+	if !shard1.Contains("item3") || shard2.Contains("item3") {
+		t.Error("Committed transaction was not applied as expected")
+	}
+}
+
+func TestTransactIsolation(t *testing.T) {
+	t.Parallel()
+
+	rr, _ := hqgoroundrobin.New("item1")
+
+	errRollback := errors.New("rollback")
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		for range 200 {
+			_ = hqgoroundrobin.Transact([]*hqgoroundrobin.RoundRobin{rr}, func(tx *hqgoroundrobin.Tx) error {
+				for i := range 100 {
+					if err := tx.Add(rr, "item"+strconv.Itoa(i+2)); err != nil {
+						return err
+					}
+				}
+
+				return errRollback
+			})
+		}
+	}()
+
+	for {
+		select {
+		case <-done:
+			return
+		default:
+		}
+
+		if rr.Contains("item2") || !rr.Contains("item1") || len(rr.Items()) != 1 {
+			t.Fatal("Observed the changes of a transaction that was rolled back")
+		}
+	}
+}
+
+func TestTransactPanic(t *testing.T) {
+	t.Parallel()
+
+	shard1, _ := hqgoroundrobin.New("item1")
+	shard2, _ := hqgoroundrobin.New("item1")
+
+	pools := []*hqgoroundrobin.RoundRobin{shard1, nil, shard2}
+
+	func() {
+		defer func() {
+			if recovered := recover(); recovered != "boom" {
+				t.Errorf("Expected the panic of fn to be resumed, got %v", recovered)
+			}
+		}()
+
+		_ = hqgoroundrobin.Transact(pools, func(tx *hqgoroundrobin.Tx) error {
+			if err := tx.Add(shard1, "item2"); err != nil {
+				return err
+			}
+
+			panic("boom")
+		})
+	}()
+
+	for i, rr := range []*hqgoroundrobin.RoundRobin{shard1, shard2} {
+		if got, want := rr.Values(), []string{"item1"}; !slices.Equal(got, want) {
+			t.Errorf("Pool %d was modified by a transaction that panicked: got %v, want %v", i+1, got, want)
+		}
+	}
+
+	if shard1.Contains("item2") {
+		t.Error("Value added by a transaction that panicked is still part of the pool")
+	}
+}
+
+func TestTxAddWeightedDuplicate(t *testing.T) {
+	t.Parallel()
+
+	strict, _ := hqgoroundrobin.NewWithOptions(hqgoroundrobin.Options{RotateAmount: 1, ErrorOnDuplicate: true}, "item1")
+	lenient, _ := hqgoroundrobin.New("item1")
+
+	err := hqgoroundrobin.Transact([]*hqgoroundrobin.RoundRobin{strict}, func(tx *hqgoroundrobin.Tx) error {
+		return tx.AddWeighted(strict, "item1", 3)
+	})
+	if !errors.Is(err, hqgoroundrobin.ErrDuplicateItem) {
+		t.Errorf("Expected ErrDuplicateItem error, got %v", err)
+	}
+
+	err = hqgoroundrobin.Transact([]*hqgoroundrobin.RoundRobin{lenient}, func(tx *hqgoroundrobin.Tx) error {
+		return tx.AddWeighted(lenient, "item1", 3)
+	})
+	if err != nil {
+		t.Errorf("Expected the duplicate to be ignored, got %v", err)
+	}
+}